@@ -0,0 +1,271 @@
+package css
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Color Css color with alpha (0 is transparent, 1 is opaque)
+type Color struct {
+	R, G, B uint8
+	A       float64
+}
+
+// White Background colors are blended against
+var White = Color{R: 255, G: 255, B: 255, A: 1}
+
+// ParseColor Parses css color: #rgb, #rgba, #rrggbb, #rrggbbaa, rgb(), rgba(), hsl(), hsla()
+// (comma or space separated, alpha after /), named colors and transparent
+func ParseColor(value string) (Color, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	if strings.HasPrefix(value, "#") {
+		return parseHexColor(value)
+	}
+
+	if value == "transparent" {
+		return Color{}, nil
+	}
+
+	if rgb, ok := namedColors[value]; ok {
+		return Color{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 1}, nil
+	}
+
+	open := strings.IndexByte(value, '(')
+
+	if open < 0 || !strings.HasSuffix(value, ")") {
+		return Color{}, fmt.Errorf("unknown color %q", value)
+	}
+
+	args, alpha, err := colorArguments(value[open+1 : len(value)-1])
+
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid color %q: %s", value, err)
+	}
+
+	var color Color
+
+	switch name := value[:open]; name {
+	case "rgb", "rgba":
+		color, err = rgbColor(args)
+	case "hsl", "hsla":
+		color, err = hslColor(args)
+	default:
+		err = fmt.Errorf("unsupported function %s", name)
+	}
+
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid color %q: %s", value, err)
+	}
+
+	color.A = 1
+
+	if alpha != "" {
+		if color.A, err = parseAlpha(alpha); err != nil {
+			return Color{}, fmt.Errorf("invalid color %q: %s", value, err)
+		}
+	}
+
+	return color, nil
+}
+
+// Blend Returns opaque color of color painted over background
+func (c Color) Blend(background Color) Color {
+	mix := func(fg uint8, bg uint8) uint8 {
+		return uint8(math.Round(float64(fg)*c.A + float64(bg)*(1-c.A)))
+	}
+
+	return Color{R: mix(c.R, background.R), G: mix(c.G, background.G), B: mix(c.B, background.B), A: 1}
+}
+
+// Hex Returns #RRGGBB color (alpha is ignored)
+func (c Color) Hex() string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
+
+// ExcelColor Returns #RRGGBB excel color of css color. Translucent colors are blended against white
+func ExcelColor(value string) (string, error) {
+	color, err := ParseColor(value)
+
+	if err != nil {
+		return "", err
+	}
+
+	return color.Blend(White).Hex(), nil
+}
+
+// FindColor Returns first color of shorthand value: "1px solid red", "url(bg.png) #fff no-repeat"
+func FindColor(value string) (Color, bool) {
+	for _, token := range SplitValue(value) {
+		if color, err := ParseColor(token); err == nil {
+			return color, true
+		}
+	}
+
+	return Color{}, false
+}
+
+// parseHexColor Parses #rgb, #rgba, #rrggbb and #rrggbbaa
+func parseHexColor(value string) (Color, error) {
+	digits := value[1:]
+
+	if len(digits) == 3 || len(digits) == 4 {
+		var expanded strings.Builder
+
+		for i := 0; i < len(digits); i++ {
+			expanded.WriteByte(digits[i])
+			expanded.WriteByte(digits[i])
+		}
+
+		digits = expanded.String()
+	}
+
+	if len(digits) != 6 && len(digits) != 8 {
+		return Color{}, fmt.Errorf("invalid color %q", value)
+	}
+
+	if len(digits) == 6 {
+		digits += "ff"
+	}
+
+	rgba, err := strconv.ParseUint(digits, 16, 32)
+
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid color %q", value)
+	}
+
+	return Color{R: uint8(rgba >> 24), G: uint8(rgba >> 16), B: uint8(rgba >> 8), A: float64(uint8(rgba)) / 255}, nil
+}
+
+// colorArguments Splits color function arguments "255, 0, 0, 0.5" or "255 0 0 / 50%" to components and alpha
+func colorArguments(text string) ([]string, string, error) {
+	alpha := ""
+
+	if slash := strings.IndexByte(text, '/'); slash >= 0 {
+		text, alpha = text[:slash], strings.TrimSpace(text[slash+1:])
+	}
+
+	args := strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+
+	if len(args) == 4 && alpha == "" {
+		args, alpha = args[:3], args[3]
+	}
+
+	if len(args) != 3 {
+		return nil, "", fmt.Errorf("3 components expected")
+	}
+
+	return args, alpha, nil
+}
+
+// rgbColor Returns color of red, green and blue components: numbers 0-255 or percentages
+func rgbColor(args []string) (Color, error) {
+	var components [3]uint8
+
+	for i, arg := range args {
+		value, err := parseNumberOrPercent(arg, 255)
+
+		if err != nil {
+			return Color{}, err
+		}
+
+		components[i] = uint8(math.Round(clamp(value, 0, 255)))
+	}
+
+	return Color{R: components[0], G: components[1], B: components[2]}, nil
+}
+
+// hslColor Returns color of hue (degrees), saturation and lightness (percentages)
+func hslColor(args []string) (Color, error) {
+	hue, err := strconv.ParseFloat(strings.TrimSuffix(args[0], "deg"), 64)
+
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid hue %q", args[0])
+	}
+
+	saturation, errS := parseNumberOrPercent(args[1], 1)
+	lightness, errL := parseNumberOrPercent(args[2], 1)
+
+	if errS != nil || errL != nil || !strings.HasSuffix(args[1], "%") || !strings.HasSuffix(args[2], "%") {
+		return Color{}, fmt.Errorf("saturation and lightness percentages expected")
+	}
+
+	hue = math.Mod(math.Mod(hue, 360)+360, 360) / 360
+	saturation = clamp(saturation, 0, 1)
+	lightness = clamp(lightness, 0, 1)
+
+	var q float64
+
+	if lightness < 0.5 {
+		q = lightness * (1 + saturation)
+	} else {
+		q = lightness + saturation - lightness*saturation
+	}
+
+	p := 2*lightness - q
+	channel := func(t float64) uint8 {
+		return uint8(math.Round(hueToRgb(p, q, t) * 255))
+	}
+
+	return Color{R: channel(hue + 1.0/3), G: channel(hue), B: channel(hue - 1.0/3)}, nil
+}
+
+// hueToRgb Returns rgb channel (0-1) of hsl color
+func hueToRgb(p float64, q float64, t float64) float64 {
+	if t < 0 {
+		t++
+	}
+
+	if t > 1 {
+		t--
+	}
+
+	switch {
+	case t < 1.0/6:
+		return p + (q-p)*6*t
+	case t < 1.0/2:
+		return q
+	case t < 2.0/3:
+		return p + (q-p)*(2.0/3-t)*6
+	}
+
+	return p
+}
+
+// parseAlpha Parses alpha: number 0-1 or percentage
+func parseAlpha(text string) (float64, error) {
+	alpha, err := parseNumberOrPercent(text, 1)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return clamp(alpha, 0, 1), nil
+}
+
+// parseNumberOrPercent Parses number or percentage of max
+func parseNumberOrPercent(text string, max float64) (float64, error) {
+	if strings.HasSuffix(text, "%") {
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(text, "%"), 64)
+
+		if err != nil {
+			return 0, fmt.Errorf("invalid percentage %q", text)
+		}
+
+		return percent / 100 * max, nil
+	}
+
+	value, err := strconv.ParseFloat(text, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", text)
+	}
+
+	return value, nil
+}
+
+func clamp(value float64, min float64, max float64) float64 {
+	return math.Max(min, math.Min(max, value))
+}
@@ -0,0 +1,87 @@
+package css
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		value string
+		color Color
+	}{
+		{"#f00", Color{255, 0, 0, 1}},
+		{"#F0A8", Color{255, 0, 170, 136.0 / 255}},
+		{"#1a2B3c", Color{26, 43, 60, 1}},
+		{"#1a2b3c80", Color{26, 43, 60, 128.0 / 255}},
+		{"rgb(255, 128, 0)", Color{255, 128, 0, 1}},
+		{"RGB(255 128 0)", Color{255, 128, 0, 1}},
+		{"rgb(100%, 50%, 0%)", Color{255, 128, 0, 1}},
+		{"rgb(300, -5, 0)", Color{255, 0, 0, 1}},
+		{"rgba(0, 0, 255, 0.5)", Color{0, 0, 255, 0.5}},
+		{"rgb(0 0 255 / 25%)", Color{0, 0, 255, 0.25}},
+		{"hsl(120, 100%, 50%)", Color{0, 255, 0, 1}},
+		{"hsla(240deg 100% 25% / 0.5)", Color{0, 0, 128, 0.5}},
+		{"red", Color{255, 0, 0, 1}},
+		{" Navy ", Color{0, 0, 128, 1}},
+		{"whitesmoke", Color{245, 245, 245, 1}},
+		{"transparent", Color{}},
+	}
+
+	for _, test := range tests {
+		color, err := ParseColor(test.value)
+
+		if err != nil {
+			t.Errorf("ParseColor(%q) error %v", test.value, err)
+			continue
+		}
+
+		if color.R != test.color.R || color.G != test.color.G || color.B != test.color.B ||
+			math.Abs(color.A-test.color.A) > 1e-9 {
+			t.Errorf("ParseColor(%q) = %v, want %v", test.value, color, test.color)
+		}
+	}
+
+	for _, value := range []string{"", "#ff", "#12345", "#ggg", "reddish", "rgb(1, 2)", "hsl(1, 2, 3)", "cmyk(1, 2, 3)"} {
+		if color, err := ParseColor(value); err == nil {
+			t.Errorf("ParseColor(%q) = %v, want error", value, color)
+		}
+	}
+}
+
+func TestExcelColorBlendsAgainstWhite(t *testing.T) {
+	tests := map[string]string{
+		"#abc":                    "#AABBCC",
+		"rgb(255, 0, 0)":          "#FF0000",
+		"rgba(0, 0, 0, 0.5)":      "#808080",
+		"rgb(0 0 255 / 0)":        "#FFFFFF",
+		"darkgreen":               "#006400",
+		"hsl(0, 100%, 50%)":       "#FF0000",
+		"hsla(0, 100%, 50%, 50%)": "#FF8080",
+	}
+
+	for value, want := range tests {
+		if color, err := ExcelColor(value); err != nil || color != want {
+			t.Errorf("ExcelColor(%q) = %q, %v, want %q", value, color, err, want)
+		}
+	}
+}
+
+func TestFindColor(t *testing.T) {
+	tests := map[string]string{
+		"1px solid red":                   "#FF0000",
+		"url(bg.png) #fff no-repeat":      "#FFFFFF",
+		"2px dashed rgb(0, 128, 0)":       "#008000",
+		"thin hsl(240, 100%, 50%) dotted": "#0000FF",
+	}
+
+	for value, want := range tests {
+		if color, ok := FindColor(value); !ok || color.Hex() != want {
+			t.Errorf("FindColor(%q) = %v, %v, want %s", value, color, ok, want)
+		}
+	}
+
+	if color, ok := FindColor("1px solid"); ok {
+		t.Errorf("FindColor of value without color = %v", color)
+	}
+}
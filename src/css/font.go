@@ -0,0 +1,109 @@
+package css
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// genericFamilies Fonts used for generic font families
+var genericFamilies = map[string]string{
+	"serif":      "Times New Roman",
+	"sans-serif": "Arial",
+	"monospace":  "Courier New",
+	"cursive":    "Comic Sans MS",
+	"fantasy":    "Impact",
+	"system-ui":  "Calibri",
+}
+
+// fontStyleKeywords Keywords of font shorthand set before font size (font style, variant, weight and stretch)
+var fontStyleKeywords = map[string]bool{
+	"normal": true, "italic": true, "oblique": true, "small-caps": true,
+	"bold": true, "bolder": true, "lighter": true,
+	"ultra-condensed": true, "extra-condensed": true, "condensed": true, "semi-condensed": true,
+	"semi-expanded": true, "expanded": true, "extra-expanded": true, "ultra-expanded": true,
+}
+
+// Font Components of font shorthand: "italic bold 12px/1.5 Arial, sans-serif". Omitted style and weight are normal
+type Font struct {
+	Style  string
+	Weight string
+	Size   string
+	Family string
+}
+
+// ParseFont Parses font shorthand. Size and family are required
+func ParseFont(value string) (Font, error) {
+	font := Font{Style: "normal", Weight: "normal"}
+	tokens := SplitValue(value)
+
+	for i, token := range tokens {
+		lower := strings.ToLower(token)
+
+		switch {
+		case lower == "italic" || lower == "oblique":
+			font.Style = lower
+		case lower == "bold" || lower == "bolder" || lower == "lighter":
+			font.Weight = lower
+		case fontStyleKeywords[lower]:
+		case isFontWeightNumber(lower):
+			font.Weight = lower
+		default:
+			font.Size = strings.SplitN(token, "/", 2)[0] // line height is ignored
+			font.Family = strings.TrimSpace(strings.Join(tokens[i+1:], " "))
+
+			if _, err := FontSizePixels(font.Size, RootFontSize); err != nil || font.Family == "" {
+				return Font{}, fmt.Errorf("invalid font %q", value)
+			}
+
+			return font, nil
+		}
+	}
+
+	return Font{}, fmt.Errorf("invalid font %q: size and family expected", value)
+}
+
+// FontFamily Returns first font of font-family list. Generic families (serif, monospace...) are mapped
+// to common fonts
+func FontFamily(value string) string {
+	for _, family := range splitOutsideBrackets(value, ',') {
+		family = strings.Trim(strings.TrimSpace(family), `"'`)
+
+		if family == "" {
+			continue
+		}
+
+		if font, ok := genericFamilies[strings.ToLower(family)]; ok {
+			return font
+		}
+
+		return family
+	}
+
+	return ""
+}
+
+// IsBold Checks font-weight is bold: bold, bolder or 600 and more. Lighter is not bold
+func IsBold(weight string) (bool, error) {
+	weight = strings.ToLower(strings.TrimSpace(weight))
+
+	switch weight {
+	case "bold", "bolder":
+		return true, nil
+	case "normal", "lighter":
+		return false, nil
+	}
+
+	if !isFontWeightNumber(weight) {
+		return false, fmt.Errorf("invalid font weight %q", weight)
+	}
+
+	number, _ := strconv.ParseFloat(weight, 64)
+	return number >= 600, nil
+}
+
+// isFontWeightNumber Checks value is numeric font weight 1-1000
+func isFontWeightNumber(value string) bool {
+	number, err := strconv.ParseFloat(value, 64)
+	return err == nil && number >= 1 && number <= 1000
+}
@@ -0,0 +1,66 @@
+package css
+
+import (
+	"testing"
+)
+
+func TestParseFont(t *testing.T) {
+	tests := []struct {
+		value string
+		font  Font
+	}{
+		{"12px Arial", Font{"normal", "normal", "12px", "Arial"}},
+		{"italic bold 12px/1.5 Arial, sans-serif", Font{"italic", "bold", "12px", "Arial, sans-serif"}},
+		{"small-caps 700 oblique 1.2em \"Times New Roman\"", Font{"oblique", "700", "1.2em", "\"Times New Roman\""}},
+		{"condensed lighter large serif", Font{"normal", "lighter", "large", "serif"}},
+	}
+
+	for _, test := range tests {
+		if font, err := ParseFont(test.value); err != nil || font != test.font {
+			t.Errorf("ParseFont(%q) = %+v, %v, want %+v", test.value, font, err, test.font)
+		}
+	}
+
+	for _, value := range []string{"", "bold", "12px", "italic Arial", "huge Arial"} {
+		if font, err := ParseFont(value); err == nil {
+			t.Errorf("ParseFont(%q) = %+v, want error", value, font)
+		}
+	}
+}
+
+func TestFontFamily(t *testing.T) {
+	tests := map[string]string{
+		"Arial":                    "Arial",
+		`"Times New Roman", serif`: "Times New Roman",
+		" 'PT Sans' , sans-serif":  "PT Sans",
+		"serif":                    "Times New Roman",
+		"Monospace, Arial":         "Courier New",
+		", system-ui":              "Calibri",
+		"":                         "",
+	}
+
+	for value, want := range tests {
+		if family := FontFamily(value); family != want {
+			t.Errorf("FontFamily(%q) = %q, want %q", value, family, want)
+		}
+	}
+}
+
+func TestIsBold(t *testing.T) {
+	tests := map[string]bool{
+		"bold": true, "BOLDER": true, "600": true, "900": true, " 700 ": true,
+		"normal": false, "lighter": false, "500": false, "1": false,
+	}
+
+	for weight, want := range tests {
+		if bold, err := IsBold(weight); err != nil || bold != want {
+			t.Errorf("IsBold(%q) = %v, %v, want %v", weight, bold, err, want)
+		}
+	}
+
+	for _, weight := range []string{"heavy", "0", "1001", ""} {
+		if _, err := IsBold(weight); err == nil {
+			t.Errorf("IsBold(%q) has no error", weight)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package css
+
+// InheritValue Value taking property value of parent element
+const InheritValue = "inherit"
+
+// InheritedProperties Properties inherited by html elements from their parents. Besides css inherited properties
+// table cells inherit background and vertical alignment of table, columns and rows (painted under cells in browsers)
+// and cell-type of cell value
+var InheritedProperties = map[string]bool{
+	"color":            true,
+	"font":             true,
+	"font-family":      true,
+	"font-size":        true,
+	"font-style":       true,
+	"font-weight":      true,
+	"text-align":       true,
+	"text-decoration":  true,
+	"white-space":      true,
+	"word-wrap":        true,
+	"overflow-wrap":    true,
+	"vertical-align":   true,
+	"background":       true,
+	"background-color": true,
+	"cell-type":        true,
+}
+
+// Inherit Returns computed declarations of element: inherited properties of parent computed declarations
+// followed by own declarations of element (overriding them). Own declarations with inherit value
+// take parent value of any property
+func Inherit(parent []Declaration, own []Declaration) []Declaration {
+	result := make([]Declaration, 0, len(parent)+len(own))
+
+	for _, declaration := range parent {
+		if InheritedProperties[declaration.Property] {
+			result = append(result, declaration)
+		}
+	}
+
+	for _, declaration := range own {
+		if declaration.Value != InheritValue {
+			result = append(result, declaration)
+			continue
+		}
+
+		if inherited, ok := LastDeclaration(parent, declaration.Property); ok {
+			result = append(result, inherited)
+		}
+	}
+
+	return result
+}
+
+// LastDeclaration Returns last (winning) declaration of property
+func LastDeclaration(declarations []Declaration, property string) (Declaration, bool) {
+	for i := len(declarations) - 1; i >= 0; i-- {
+		if declarations[i].Property == property {
+			return declarations[i], true
+		}
+	}
+
+	return Declaration{}, false
+}
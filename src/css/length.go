@@ -0,0 +1,120 @@
+package css
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PixelsPerInch Css pixels in inch
+const PixelsPerInch = 96.0
+
+// PointsPerPixel Typographic points in css pixel
+const PointsPerPixel = 72.0 / PixelsPerInch
+
+// RootFontSize Font size of root element in css pixels, rem lengths are relative to it
+const RootFontSize = 16.0
+
+// pixelsPerUnit Css pixels in one unit of absolute length units
+var pixelsPerUnit = map[string]float64{
+	"px": 1,
+	"pt": PixelsPerInch / 72,
+	"pc": PixelsPerInch / 6,
+	"in": PixelsPerInch,
+	"cm": PixelsPerInch / 2.54,
+	"mm": PixelsPerInch / 25.4,
+	"q":  PixelsPerInch / 101.6,
+}
+
+// fontSizeKeywords Absolute font size keywords in css pixels
+var fontSizeKeywords = map[string]float64{
+	"xx-small":  9,
+	"x-small":   10,
+	"small":     13,
+	"medium":    16,
+	"large":     18,
+	"x-large":   24,
+	"xx-large":  32,
+	"xxx-large": 48,
+}
+
+// Length Css length: number with unit (px, pt, pc, in, cm, mm, q, em, rem or %)
+type Length struct {
+	Value float64
+	Unit  string
+}
+
+// ParseLength Parses css length: "12.5px", "10pt", "1.2em", "2cm", "30%". Numbers without unit are pixels
+// (like html width and height attributes)
+func ParseLength(value string) (Length, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	end := len(value)
+
+	for end > 0 && (value[end-1] >= 'a' && value[end-1] <= 'z' || value[end-1] == '%') {
+		end--
+	}
+
+	number, err := strconv.ParseFloat(value[:end], 64)
+
+	if err != nil {
+		return Length{}, fmt.Errorf("invalid length %q", value)
+	}
+
+	unit := value[end:]
+
+	switch unit {
+	case "":
+		unit = "px"
+	case "em", "rem", "%":
+	default:
+		if _, ok := pixelsPerUnit[unit]; !ok {
+			return Length{}, fmt.Errorf("unsupported unit of length %q", value)
+		}
+	}
+
+	return Length{Value: number, Unit: unit}, nil
+}
+
+// IsRelative Checks length depends on font size or percentage base
+func (l Length) IsRelative() bool {
+	return l.Unit == "em" || l.Unit == "rem" || l.Unit == "%"
+}
+
+// Pixels Returns length in css pixels. Em lengths are relative to fontSize, percentages to percentBase (in pixels)
+func (l Length) Pixels(fontSize float64, percentBase float64) float64 {
+	switch l.Unit {
+	case "em":
+		return l.Value * fontSize
+	case "rem":
+		return l.Value * RootFontSize
+	case "%":
+		return l.Value / 100 * percentBase
+	}
+
+	return l.Value * pixelsPerUnit[l.Unit]
+}
+
+// FontSizePixels Returns font size value (length, percentage or keyword) in css pixels.
+// Relative sizes (em, %, smaller, larger) are relative to parentSize
+func FontSizePixels(value string, parentSize float64) (float64, error) {
+	keyword := strings.ToLower(strings.TrimSpace(value))
+
+	if size, ok := fontSizeKeywords[keyword]; ok {
+		return size, nil
+	}
+
+	switch keyword {
+	case "smaller":
+		return parentSize / 1.2, nil
+	case "larger":
+		return parentSize * 1.2, nil
+	}
+
+	length, err := ParseLength(value)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return length.Pixels(parentSize, parentSize), nil
+}
@@ -0,0 +1,74 @@
+package css
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseLengthUnits(t *testing.T) {
+	tests := []struct {
+		value  string
+		length Length
+		pixels float64 // with 20px font size and 200px percentage base
+	}{
+		{"12px", Length{12, "px"}, 12},
+		{"12", Length{12, "px"}, 12},
+		{" 12.5PX ", Length{12.5, "px"}, 12.5},
+		{"-4px", Length{-4, "px"}, -4},
+		{".5in", Length{0.5, "in"}, 48},
+		{"9pt", Length{9, "pt"}, 12},
+		{"1pc", Length{1, "pc"}, 16},
+		{"2.54cm", Length{2.54, "cm"}, 96},
+		{"25.4mm", Length{25.4, "mm"}, 96},
+		{"101.6q", Length{101.6, "q"}, 96},
+		{"1.5em", Length{1.5, "em"}, 30},
+		{"2rem", Length{2, "rem"}, 32},
+		{"30%", Length{30, "%"}, 60},
+	}
+
+	for _, test := range tests {
+		length, err := ParseLength(test.value)
+
+		if err != nil {
+			t.Errorf("ParseLength(%q) error %v", test.value, err)
+			continue
+		}
+
+		if length != test.length {
+			t.Errorf("ParseLength(%q) = %v, want %v", test.value, length, test.length)
+		}
+
+		if pixels := length.Pixels(20, 200); math.Abs(pixels-test.pixels) > 1e-9 {
+			t.Errorf("%q is %v pixels, want %v", test.value, pixels, test.pixels)
+		}
+	}
+
+	for _, value := range []string{"", "px", "auto", "12vw", "12 px", "1e"} {
+		if length, err := ParseLength(value); err == nil {
+			t.Errorf("ParseLength(%q) = %v, want error", value, length)
+		}
+	}
+}
+
+func TestFontSizePixels(t *testing.T) {
+	tests := []struct {
+		value  string
+		pixels float64
+	}{
+		{"medium", 16},
+		{"X-Large", 24},
+		{"smaller", 10},
+		{"larger", 14.4},
+		{"150%", 18},
+		{"2em", 24},
+		{"12pt", 16},
+	}
+
+	for _, test := range tests {
+		pixels, err := FontSizePixels(test.value, 12)
+
+		if err != nil || math.Abs(pixels-test.pixels) > 1e-9 {
+			t.Errorf("FontSizePixels(%q, 12) = %v, %v, want %v", test.value, pixels, err, test.pixels)
+		}
+	}
+}
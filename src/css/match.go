@@ -0,0 +1,301 @@
+package css
+
+import (
+	"github.com/jbowtie/gokogiri/xml"
+	"sort"
+	"strings"
+	"unsafe"
+)
+
+// siblingPosition Position of element among element children of its parent
+type siblingPosition struct {
+	index     int // one based
+	count     int
+	typeIndex int // one based among siblings with the same tag
+	typeCount int
+}
+
+// Matcher Matches stylesheet rules to html elements. Sibling positions used by structural pseudo-classes
+// are cached, so Matcher is bound to one document
+type Matcher struct {
+	stylesheet *Stylesheet
+	positions  map[unsafe.Pointer]siblingPosition
+}
+
+// NewMatcher Creates matcher of stylesheet rules
+func NewMatcher(stylesheet *Stylesheet) *Matcher {
+	return &Matcher{stylesheet: stylesheet, positions: make(map[unsafe.Pointer]siblingPosition)}
+}
+
+// Declarations Returns declarations applied to element in cascade order (later declarations win):
+// stylesheet rules by specificity and source order, then inline style attribute, then !important
+// declarations in the same order
+func (m *Matcher) Declarations(node xml.Node) []Declaration {
+	var matched []*Rule
+
+	if m.stylesheet != nil {
+		for _, rule := range m.stylesheet.Rules {
+			if m.Matches(rule.Selector, node) {
+				matched = append(matched, rule)
+			}
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].Selector.Specificity != matched[j].Selector.Specificity {
+			return matched[i].Selector.Specificity.Less(matched[j].Selector.Specificity)
+		}
+
+		return matched[i].order < matched[j].order
+	})
+
+	var inline []Declaration
+
+	if style := node.Attribute("style"); style != nil {
+		inline = ParseDeclarations(style.Value())
+	}
+
+	var result, important []Declaration
+
+	for _, rule := range matched {
+		for _, declaration := range rule.Declarations {
+			if declaration.Important {
+				important = append(important, declaration)
+			} else {
+				result = append(result, declaration)
+			}
+		}
+	}
+
+	var inlineImportant []Declaration
+
+	for _, declaration := range inline {
+		if declaration.Important {
+			inlineImportant = append(inlineImportant, declaration)
+		} else {
+			result = append(result, declaration)
+		}
+	}
+
+	result = append(result, important...)
+	return append(result, inlineImportant...)
+}
+
+// Matches Checks selector matches element
+func (m *Matcher) Matches(selector *Selector, node xml.Node) bool {
+	return m.matchesFrom(selector, len(selector.compounds)-1, node)
+}
+
+// matchesFrom Checks compounds up to last match element and its ancestors or siblings by combinators
+func (m *Matcher) matchesFrom(selector *Selector, last int, node xml.Node) bool {
+	if !m.matchesCompound(&selector.compounds[last], node) {
+		return false
+	}
+
+	if last == 0 {
+		return true
+	}
+
+	switch selector.combinators[last-1] {
+	case combinatorChild:
+		parent := parentElement(node)
+		return parent != nil && m.matchesFrom(selector, last-1, parent)
+	case combinatorDescendant:
+		for parent := parentElement(node); parent != nil; parent = parentElement(parent) {
+			if m.matchesFrom(selector, last-1, parent) {
+				return true
+			}
+		}
+	case combinatorAdjacent:
+		sibling := previousElement(node)
+		return sibling != nil && m.matchesFrom(selector, last-1, sibling)
+	case combinatorSibling:
+		for sibling := previousElement(node); sibling != nil; sibling = previousElement(sibling) {
+			if m.matchesFrom(selector, last-1, sibling) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchesCompound Checks element matches all parts of compound selector
+func (m *Matcher) matchesCompound(c *compound, node xml.Node) bool {
+	if c.tag != "" && strings.ToLower(node.Name()) != c.tag {
+		return false
+	}
+
+	for _, id := range c.ids {
+		if node.Attr("id") != id {
+			return false
+		}
+	}
+
+	if len(c.classes) > 0 {
+		classes := strings.Fields(node.Attr("class"))
+
+		for _, class := range c.classes {
+			if !containsString(classes, class) {
+				return false
+			}
+		}
+	}
+
+	for _, attr := range c.attrs {
+		if !attr.matches(node) {
+			return false
+		}
+	}
+
+	for _, pseudo := range c.pseudos {
+		if !m.matchesPseudo(pseudo, node) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matches Checks element attribute matches attribute selector
+func (a attrSelector) matches(node xml.Node) bool {
+	attribute := node.Attribute(a.name)
+
+	if attribute == nil {
+		return false
+	}
+
+	if a.op == "" {
+		return true
+	}
+
+	value, expected := attribute.Value(), a.value
+
+	if a.caseInsensitive {
+		value, expected = strings.ToLower(value), strings.ToLower(expected)
+	}
+
+	switch a.op {
+	case "=":
+		return value == expected
+	case "~=":
+		return containsString(strings.Fields(value), expected)
+	case "|=":
+		return value == expected || strings.HasPrefix(value, expected+"-")
+	case "^=":
+		return expected != "" && strings.HasPrefix(value, expected)
+	case "$=":
+		return expected != "" && strings.HasSuffix(value, expected)
+	case "*=":
+		return expected != "" && strings.Contains(value, expected)
+	}
+
+	return false
+}
+
+// matchesPseudo Checks element position among siblings matches structural pseudo-class
+func (m *Matcher) matchesPseudo(pseudo pseudoSelector, node xml.Node) bool {
+	position := m.position(node)
+
+	switch pseudo.name {
+	case "first-child":
+		return position.index == 1
+	case "last-child":
+		return position.index == position.count
+	case "only-child":
+		return position.count == 1
+	case "first-of-type":
+		return position.typeIndex == 1
+	case "last-of-type":
+		return position.typeIndex == position.typeCount
+	case "nth-child":
+		return nthMatches(pseudo.a, pseudo.b, position.index)
+	case "nth-last-child":
+		return nthMatches(pseudo.a, pseudo.b, position.count-position.index+1)
+	case "nth-of-type":
+		return nthMatches(pseudo.a, pseudo.b, position.typeIndex)
+	case "nth-last-of-type":
+		return nthMatches(pseudo.a, pseudo.b, position.typeCount-position.typeIndex+1)
+	}
+
+	return false
+}
+
+// position Returns cached position of element among siblings. Positions of all siblings are computed at once
+func (m *Matcher) position(node xml.Node) siblingPosition {
+	if position, ok := m.positions[node.NodePtr()]; ok {
+		return position
+	}
+
+	parent := node.Parent()
+
+	if parent == nil {
+		return siblingPosition{index: 1, count: 1, typeIndex: 1, typeCount: 1}
+	}
+
+	var siblings []xml.Node
+	typeCounts := make(map[string]int)
+
+	for child := parent.FirstChild(); child != nil; child = child.NextSibling() {
+		if child.NodeType() == xml.XML_ELEMENT_NODE {
+			siblings = append(siblings, child)
+			typeCounts[child.Name()]++
+		}
+	}
+
+	typeIndexes := make(map[string]int)
+
+	for i, sibling := range siblings {
+		typeIndexes[sibling.Name()]++
+		m.positions[sibling.NodePtr()] = siblingPosition{
+			index:     i + 1,
+			count:     len(siblings),
+			typeIndex: typeIndexes[sibling.Name()],
+			typeCount: typeCounts[sibling.Name()],
+		}
+	}
+
+	return m.positions[node.NodePtr()]
+}
+
+// nthMatches Checks index is a*n+b for some n >= 0
+func nthMatches(a int, b int, index int) bool {
+	if a == 0 {
+		return index == b
+	}
+
+	n := index - b
+	return n%a == 0 && n/a >= 0
+}
+
+// parentElement Returns parent element, nil for root element
+func parentElement(node xml.Node) xml.Node {
+	parent := node.Parent()
+
+	if parent == nil || parent.NodeType() != xml.XML_ELEMENT_NODE {
+		return nil
+	}
+
+	return parent
+}
+
+// previousElement Returns previous element sibling
+func previousElement(node xml.Node) xml.Node {
+	for sibling := node.PreviousSibling(); sibling != nil; sibling = sibling.PreviousSibling() {
+		if sibling.NodeType() == xml.XML_ELEMENT_NODE {
+			return sibling
+		}
+	}
+
+	return nil
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
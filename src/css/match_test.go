@@ -0,0 +1,114 @@
+package css
+
+import (
+	"github.com/jbowtie/gokogiri"
+	"github.com/jbowtie/gokogiri/xml"
+	"testing"
+)
+
+func TestNthMatches(t *testing.T) {
+	tests := []struct {
+		a       int
+		b       int
+		matches []int // of indexes 1..10
+	}{
+		{2, 1, []int{1, 3, 5, 7, 9}},
+		{2, 0, []int{2, 4, 6, 8, 10}},
+		{0, 3, []int{3}},
+		{1, 0, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{-1, 3, []int{1, 2, 3}},
+		{3, -1, []int{2, 5, 8}},
+		{-2, 5, []int{1, 3, 5}},
+		{0, -1, nil},
+	}
+
+	for _, test := range tests {
+		var matches []int
+
+		for index := 1; index <= 10; index++ {
+			if nthMatches(test.a, test.b, index) {
+				matches = append(matches, index)
+			}
+		}
+
+		if len(matches) != len(test.matches) {
+			t.Errorf("%dn%+d matches %v, want %v", test.a, test.b, matches, test.matches)
+			continue
+		}
+
+		for i := range matches {
+			if matches[i] != test.matches[i] {
+				t.Errorf("%dn%+d matches %v, want %v", test.a, test.b, matches, test.matches)
+				break
+			}
+		}
+	}
+}
+
+// findElement Returns the first element of html document matching xpath
+func findElement(t *testing.T, html string, xpath string) xml.Node {
+	doc, err := gokogiri.ParseHtml([]byte(html))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(doc.Free)
+	nodes, err := doc.Root().Search(xpath)
+
+	if err != nil || len(nodes) == 0 {
+		t.Fatalf("no element %s: %v", xpath, err)
+	}
+
+	return nodes[0]
+}
+
+func TestMatcherCascadeOrder(t *testing.T) {
+	stylesheet, errs := Parse(`
+		td.money { color: red !important; text-align: right }
+		#total { color: blue; text-align: center }
+		td { color: green !important; font-weight: bold }
+		tr:nth-child(2) td { font-weight: normal }
+		td { text-align: left }
+	`)
+
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	td := findElement(t, `<table><tr><td>a</td></tr><tr><td id="total" class="money"
+		style="color: black; font-weight: 600 !important; text-align: justify">1</td></tr></table>`, "//td[@id]")
+
+	tests := []struct {
+		property string
+		want     string
+	}{
+		{"color", "red"},          // !important of more specific rule wins over !important of later rule and inline
+		{"text-align", "justify"}, // inline wins over rules with higher specificity
+		{"font-weight", "600"},    // inline !important wins over everything
+	}
+
+	declarations := NewMatcher(stylesheet).Declarations(td)
+
+	for _, test := range tests {
+		declaration, ok := LastDeclaration(declarations, test.property)
+
+		if !ok || declaration.Value != test.want {
+			t.Errorf("%s is %q, want %q (declarations %v)", test.property, declaration.Value, test.want, declarations)
+		}
+	}
+
+	want := []string{"font-weight: bold", "text-align: left", "text-align: right", "font-weight: normal",
+		"color: blue", "text-align: center", "color: black", "text-align: justify", "color: green", "color: red",
+		"font-weight: 600"}
+
+	if len(declarations) != len(want) {
+		t.Fatalf("declarations %v, want %v", declarations, want)
+	}
+
+	for i, declaration := range declarations {
+		if got := declaration.Property + ": " + declaration.Value; got != want[i] {
+			t.Errorf("declaration %d is %q, want %q", i, got, want[i])
+		}
+	}
+}
@@ -0,0 +1,399 @@
+package css
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Combinators between compound selectors
+const (
+	combinatorDescendant = ' '
+	combinatorChild      = '>'
+	combinatorAdjacent   = '+'
+	combinatorSibling    = '~'
+)
+
+// Specificity Selector specificity: ids, classes (attributes, pseudo-classes), types
+type Specificity [3]int
+
+// Less Checks specificity is lower than other
+func (s Specificity) Less(other Specificity) bool {
+	for i := range s {
+		if s[i] != other[i] {
+			return s[i] < other[i]
+		}
+	}
+
+	return false
+}
+
+// Selector Complex selector: compound selectors joined by combinators (td, table.report > tr:nth-child(odd) td)
+type Selector struct {
+	Text        string
+	Specificity Specificity
+	compounds   []compound // from left to right
+	combinators []byte     // combinators[i] joins compounds[i] and compounds[i+1]
+}
+
+// compound Compound selector: tag, id, classes, attributes and pseudo-classes of one element
+type compound struct {
+	tag     string // lower case, empty for *
+	ids     []string
+	classes []string
+	attrs   []attrSelector
+	pseudos []pseudoSelector
+}
+
+// attrSelector Attribute selector: [name], [name=value], [name~=value], [name|=value],
+// [name^=value], [name$=value], [name*=value]. Flag i compares values case-insensitive
+type attrSelector struct {
+	name            string
+	op              string
+	value           string
+	caseInsensitive bool
+}
+
+// pseudoSelector Structural pseudo-class. first-child, last-child, nth-child(an+b), nth-last-child(an+b),
+// nth-of-type(an+b), nth-last-of-type(an+b), only-child
+type pseudoSelector struct {
+	name string
+	a, b int
+}
+
+// ParseSelectorList Parses comma separated selectors
+func ParseSelectorList(text string) ([]*Selector, error) {
+	var result []*Selector
+
+	for _, part := range splitOutsideBrackets(text, ',') {
+		selector, err := ParseSelector(part)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, selector)
+	}
+
+	return result, nil
+}
+
+// ParseSelector Parses complex selector
+func ParseSelector(text string) (*Selector, error) {
+	text = strings.TrimSpace(text)
+	selector := &Selector{Text: text}
+	p := &selectorParser{text: text}
+
+	if text == "" {
+		return nil, fmt.Errorf("empty selector")
+	}
+
+	for {
+		c, err := p.compound()
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %s", text, err)
+		}
+
+		selector.compounds = append(selector.compounds, c)
+		selector.Specificity[0] += len(c.ids)
+		selector.Specificity[1] += len(c.classes) + len(c.attrs) + len(c.pseudos)
+
+		if c.tag != "" {
+			selector.Specificity[2]++
+		}
+
+		combinator, ok := p.combinator()
+
+		if !ok {
+			break
+		}
+
+		selector.combinators = append(selector.combinators, combinator)
+	}
+
+	if p.pos < len(p.text) {
+		return nil, fmt.Errorf("invalid selector %q: unexpected %q", text, p.text[p.pos:])
+	}
+
+	return selector, nil
+}
+
+// selectorParser Scanner of selector text
+type selectorParser struct {
+	text string
+	pos  int
+}
+
+// combinator Reads combinator between compound selectors. Returns false at the end of selector
+func (p *selectorParser) combinator() (byte, bool) {
+	start := p.pos
+	p.pos = skipSpaces(p.text, p.pos)
+
+	if p.pos >= len(p.text) {
+		return 0, false
+	}
+
+	switch c := p.text[p.pos]; c {
+	case combinatorChild, combinatorAdjacent, combinatorSibling:
+		p.pos = skipSpaces(p.text, p.pos+1)
+		return c, true
+	}
+
+	if p.pos > start {
+		return combinatorDescendant, true
+	}
+
+	return 0, false
+}
+
+// compound Reads compound selector
+func (p *selectorParser) compound() (compound, error) {
+	var c compound
+	empty := true
+
+	if p.pos < len(p.text) && p.text[p.pos] == '*' {
+		p.pos++
+		empty = false
+	} else if name := p.ident(); name != "" {
+		c.tag = strings.ToLower(name)
+		empty = false
+	}
+
+	for p.pos < len(p.text) {
+		switch p.text[p.pos] {
+		case '#':
+			p.pos++
+			id := p.ident()
+
+			if id == "" {
+				return c, fmt.Errorf("id expected at %d", p.pos)
+			}
+
+			c.ids = append(c.ids, id)
+		case '.':
+			p.pos++
+			class := p.ident()
+
+			if class == "" {
+				return c, fmt.Errorf("class expected at %d", p.pos)
+			}
+
+			c.classes = append(c.classes, class)
+		case '[':
+			attr, err := p.attribute()
+
+			if err != nil {
+				return c, err
+			}
+
+			c.attrs = append(c.attrs, attr)
+		case ':':
+			pseudo, err := p.pseudo()
+
+			if err != nil {
+				return c, err
+			}
+
+			c.pseudos = append(c.pseudos, pseudo)
+		default:
+			if empty {
+				return c, fmt.Errorf("unexpected %q", p.text[p.pos:])
+			}
+
+			return c, nil
+		}
+
+		empty = false
+	}
+
+	if empty {
+		return c, fmt.Errorf("selector expected at the end")
+	}
+
+	return c, nil
+}
+
+// attribute Reads [name op value i]
+func (p *selectorParser) attribute() (attrSelector, error) {
+	end := indexOutsideQuotes(p.text, p.pos, ']')
+
+	if end < 0 {
+		return attrSelector{}, fmt.Errorf("unclosed attribute selector")
+	}
+
+	body := strings.TrimSpace(p.text[p.pos+1 : end])
+	p.pos = end + 1
+
+	opStart := strings.IndexAny(body, "=~|^$*")
+
+	if opStart < 0 {
+		if !isIdent(body) {
+			return attrSelector{}, fmt.Errorf("invalid attribute name %q", body)
+		}
+
+		return attrSelector{name: strings.ToLower(body)}, nil
+	}
+
+	attr := attrSelector{name: strings.ToLower(strings.TrimSpace(body[:opStart]))}
+	rest := body[opStart:]
+
+	if rest[0] == '=' {
+		attr.op, rest = "=", rest[1:]
+	} else if len(rest) > 1 && rest[1] == '=' {
+		attr.op, rest = rest[:2], rest[2:]
+	} else {
+		return attrSelector{}, fmt.Errorf("invalid attribute selector [%s]", body)
+	}
+
+	rest = strings.TrimSpace(rest)
+
+	if strings.HasSuffix(rest, " i") || strings.HasSuffix(rest, " I") {
+		attr.caseInsensitive = true
+		rest = strings.TrimSpace(rest[:len(rest)-2])
+	}
+
+	if len(rest) >= 2 && (rest[0] == '"' || rest[0] == '\'') && rest[len(rest)-1] == rest[0] {
+		rest = rest[1 : len(rest)-1]
+	} else if !isIdent(rest) {
+		return attrSelector{}, fmt.Errorf("invalid attribute value %q", rest)
+	}
+
+	if !isIdent(attr.name) {
+		return attrSelector{}, fmt.Errorf("invalid attribute name %q", attr.name)
+	}
+
+	attr.value = rest
+	return attr, nil
+}
+
+// pseudo Reads structural pseudo-class
+func (p *selectorParser) pseudo() (pseudoSelector, error) {
+	p.pos++ // :
+	name := strings.ToLower(p.ident())
+
+	switch name {
+	case "first-child", "last-child", "only-child", "first-of-type", "last-of-type":
+		return pseudoSelector{name: name}, nil
+	case "nth-child", "nth-last-child", "nth-of-type", "nth-last-of-type":
+		if p.pos >= len(p.text) || p.text[p.pos] != '(' {
+			return pseudoSelector{}, fmt.Errorf(":%s argument expected", name)
+		}
+
+		end := strings.IndexByte(p.text[p.pos:], ')')
+
+		if end < 0 {
+			return pseudoSelector{}, fmt.Errorf("unclosed :%s", name)
+		}
+
+		a, b, err := parseNth(p.text[p.pos+1 : p.pos+end])
+		p.pos += end + 1
+
+		if err != nil {
+			return pseudoSelector{}, err
+		}
+
+		return pseudoSelector{name: name, a: a, b: b}, nil
+	}
+
+	return pseudoSelector{}, fmt.Errorf("unsupported pseudo-class :%s", name)
+}
+
+// ident Reads identifier
+func (p *selectorParser) ident() string {
+	start := p.pos
+
+	for p.pos < len(p.text) && isIdentChar(p.text[p.pos]) {
+		p.pos++
+	}
+
+	return p.text[start:p.pos]
+}
+
+// parseNth Parses an+b argument: odd, even, 3, 2n+1, -n+3
+func parseNth(text string) (int, int, error) {
+	text = strings.ToLower(strings.ReplaceAll(text, " ", ""))
+
+	switch text {
+	case "odd":
+		return 2, 1, nil
+	case "even":
+		return 2, 0, nil
+	}
+
+	n := strings.IndexByte(text, 'n')
+
+	if n < 0 {
+		b, err := strconv.Atoi(text)
+
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid nth argument %q", text)
+		}
+
+		return 0, b, nil
+	}
+
+	var a, b int
+	var err error
+
+	switch text[:n] {
+	case "", "+":
+		a = 1
+	case "-":
+		a = -1
+	default:
+		if a, err = strconv.Atoi(text[:n]); err != nil {
+			return 0, 0, fmt.Errorf("invalid nth argument %q", text)
+		}
+	}
+
+	if rest := text[n+1:]; rest != "" {
+		if b, err = strconv.Atoi(rest); err != nil || (rest[0] != '+' && rest[0] != '-') {
+			return 0, 0, fmt.Errorf("invalid nth argument %q", text)
+		}
+	}
+
+	return a, b, nil
+}
+
+// splitOutsideBrackets Splits text by separator outside of (), [] and quotes
+func splitOutsideBrackets(text string, separator byte) []string {
+	var parts []string
+	depth, start := 0, 0
+
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case '"', '\'':
+			i = quoteEnd(text, i)
+		case separator:
+			if depth == 0 {
+				parts = append(parts, text[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, text[start:])
+}
+
+func isIdentChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c >= 0x80
+}
+
+func isIdent(text string) bool {
+	if text == "" {
+		return false
+	}
+
+	for i := 0; i < len(text); i++ {
+		if !isIdentChar(text[i]) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,94 @@
+package css
+
+import (
+	"testing"
+)
+
+func TestParseNth(t *testing.T) {
+	tests := []struct {
+		text string
+		a    int
+		b    int
+	}{
+		{"odd", 2, 1},
+		{"even", 2, 0},
+		{"EVEN", 2, 0},
+		{"3", 0, 3},
+		{"-2", 0, -2},
+		{"n", 1, 0},
+		{"+n", 1, 0},
+		{"-n+3", -1, 3},
+		{"2n+1", 2, 1},
+		{"2n - 1", 2, -1},
+		{"3n", 3, 0},
+		{"-2n+5", -2, 5},
+	}
+
+	for _, test := range tests {
+		a, b, err := parseNth(test.text)
+
+		if err != nil {
+			t.Errorf("parseNth(%q) error %v", test.text, err)
+			continue
+		}
+
+		if a != test.a || b != test.b {
+			t.Errorf("parseNth(%q) = %d, %d, want %d, %d", test.text, a, b, test.a, test.b)
+		}
+	}
+
+	for _, text := range []string{"", "first", "n2", "2n1", "2x+1", "2n+", "--n"} {
+		if a, b, err := parseNth(text); err == nil {
+			t.Errorf("parseNth(%q) = %d, %d, want error", text, a, b)
+		}
+	}
+}
+
+func TestSpecificityLess(t *testing.T) {
+	tests := []struct {
+		s     Specificity
+		other Specificity
+		less  bool
+	}{
+		{Specificity{0, 0, 1}, Specificity{0, 0, 2}, true},
+		{Specificity{0, 0, 9}, Specificity{0, 1, 0}, true},
+		{Specificity{0, 9, 9}, Specificity{1, 0, 0}, true},
+		{Specificity{1, 0, 0}, Specificity{0, 9, 9}, false},
+		{Specificity{0, 1, 1}, Specificity{0, 1, 1}, false},
+		{Specificity{0, 1, 2}, Specificity{0, 1, 1}, false},
+	}
+
+	for _, test := range tests {
+		if less := test.s.Less(test.other); less != test.less {
+			t.Errorf("%v.Less(%v) = %v, want %v", test.s, test.other, less, test.less)
+		}
+	}
+}
+
+func TestParseSelectorSpecificity(t *testing.T) {
+	tests := []struct {
+		text        string
+		specificity Specificity
+	}{
+		{"td", Specificity{0, 0, 1}},
+		{"*", Specificity{0, 0, 0}},
+		{".money", Specificity{0, 1, 0}},
+		{"#total", Specificity{1, 0, 0}},
+		{"td[data-kind=\"money\"]", Specificity{0, 1, 1}},
+		{"table.report > tr:nth-child(odd) td", Specificity{0, 2, 3}},
+		{"#report td.money:first-child", Specificity{1, 2, 1}},
+	}
+
+	for _, test := range tests {
+		selector, err := ParseSelector(test.text)
+
+		if err != nil {
+			t.Errorf("ParseSelector(%q) error %v", test.text, err)
+			continue
+		}
+
+		if selector.Specificity != test.specificity {
+			t.Errorf("ParseSelector(%q) specificity %v, want %v", test.text, selector.Specificity, test.specificity)
+		}
+	}
+}
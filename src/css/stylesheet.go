@@ -0,0 +1,239 @@
+// Package css Parses css stylesheets and inline styles and resolves declarations applied to html elements
+// by selectors, specificity and source order, like browsers do.
+//
+// At-rules (@media, @font-face, @import...) are ignored.
+package css
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Declaration Css property with its value
+type Declaration struct {
+	Property  string // lower case
+	Value     string
+	Important bool
+}
+
+// Rule Style rule with one selector. Rule "th, td {...}" is split to rules for th and td
+type Rule struct {
+	Selector     *Selector
+	Declarations []Declaration
+	order        int // position in stylesheets, later rules win over rules with the same specificity
+}
+
+// Stylesheet Style rules in source order
+type Stylesheet struct {
+	Rules []*Rule
+}
+
+// Parse Parses stylesheet source. Rules with invalid selectors are skipped (like browsers do)
+// and returned as errors
+func Parse(source string) (*Stylesheet, []error) {
+	stylesheet := &Stylesheet{}
+	errs := stylesheet.Add(source)
+	return stylesheet, errs
+}
+
+// Add Parses stylesheet source and appends its rules after existing ones
+func (s *Stylesheet) Add(source string) []error {
+	var errs []error
+	source = stripComments(source)
+
+	for pos := 0; pos < len(source); {
+		pos = skipSpaces(source, pos)
+
+		if pos >= len(source) {
+			break
+		}
+
+		if source[pos] == '@' {
+			pos = skipAtRule(source, pos)
+			continue
+		}
+
+		open := indexOutsideQuotes(source, pos, '{')
+
+		if open < 0 {
+			errs = append(errs, fmt.Errorf("unexpected end of stylesheet after %q", strings.TrimSpace(source[pos:])))
+			break
+		}
+
+		end := blockEnd(source, open)
+		selectorText := strings.TrimSpace(source[pos:open])
+		declarations := ParseDeclarations(source[open+1 : end])
+		pos = end + 1
+
+		selectors, err := ParseSelectorList(selectorText)
+
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, selector := range selectors {
+			s.Rules = append(s.Rules, &Rule{Selector: selector, Declarations: declarations, order: len(s.Rules)})
+		}
+	}
+
+	return errs
+}
+
+// ParseDeclarations Parses declarations of style attribute or rule block: "color: red; font-size: 12px"
+func ParseDeclarations(style string) []Declaration {
+	var result []Declaration
+	style = stripComments(style)
+
+	for pos := 0; pos < len(style); {
+		end := indexOutsideQuotes(style, pos, ';')
+
+		if end < 0 {
+			end = len(style)
+		}
+
+		if declaration, ok := parseDeclaration(style[pos:end]); ok {
+			result = append(result, declaration)
+		}
+
+		pos = end + 1
+	}
+
+	return result
+}
+
+// parseDeclaration Parses "property: value !important"
+func parseDeclaration(text string) (Declaration, bool) {
+	colon := strings.IndexByte(text, ':')
+
+	if colon < 0 {
+		return Declaration{}, false
+	}
+
+	declaration := Declaration{
+		Property: strings.ToLower(strings.TrimSpace(text[:colon])),
+		Value:    strings.TrimSpace(text[colon+1:]),
+	}
+
+	if i := strings.LastIndexByte(declaration.Value, '!'); i >= 0 &&
+		strings.EqualFold(strings.TrimSpace(declaration.Value[i+1:]), "important") {
+		declaration.Important = true
+		declaration.Value = strings.TrimSpace(declaration.Value[:i])
+	}
+
+	if declaration.Property == "" || declaration.Value == "" {
+		return Declaration{}, false
+	}
+
+	return declaration, true
+}
+
+// stripComments Removes /* comments */
+func stripComments(source string) string {
+	if !strings.Contains(source, "/*") {
+		return source
+	}
+
+	var builder strings.Builder
+
+	for {
+		start := strings.Index(source, "/*")
+
+		if start < 0 {
+			builder.WriteString(source)
+			break
+		}
+
+		builder.WriteString(source[:start])
+		end := strings.Index(source[start+2:], "*/")
+
+		if end < 0 {
+			break // unclosed comment lasts to the end of stylesheet
+		}
+
+		builder.WriteByte(' ')
+		source = source[start+2+end+2:]
+	}
+
+	return builder.String()
+}
+
+// skipAtRule Returns position after at-rule: after ; of statement or after its block
+func skipAtRule(source string, pos int) int {
+	for i := pos; i < len(source); i++ {
+		switch source[i] {
+		case ';':
+			return i + 1
+		case '{':
+			return blockEnd(source, i) + 1
+		case '"', '\'':
+			i = quoteEnd(source, i)
+		}
+	}
+
+	return len(source)
+}
+
+// blockEnd Returns position of } closing block opened at open. Unclosed block lasts to the end of source
+func blockEnd(source string, open int) int {
+	depth := 0
+
+	for i := open; i < len(source); i++ {
+		switch source[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+
+			if depth == 0 {
+				return i
+			}
+		case '"', '\'':
+			i = quoteEnd(source, i)
+		}
+	}
+
+	return len(source)
+}
+
+// indexOutsideQuotes Returns position of c from pos skipping quoted strings, -1 when not found
+func indexOutsideQuotes(source string, pos int, c byte) int {
+	for i := pos; i < len(source); i++ {
+		switch source[i] {
+		case c:
+			return i
+		case '"', '\'':
+			i = quoteEnd(source, i)
+		}
+	}
+
+	return -1
+}
+
+// quoteEnd Returns position of quote closing string started at pos
+func quoteEnd(source string, pos int) int {
+	quote := source[pos]
+
+	for i := pos + 1; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+
+	return len(source)
+}
+
+func skipSpaces(source string, pos int) int {
+	for pos < len(source) && isSpace(source[pos]) {
+		pos++
+	}
+
+	return pos
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
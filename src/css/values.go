@@ -0,0 +1,59 @@
+package css
+
+// SplitValue Splits property value to space separated components. Spaces inside of functions
+// (rgb(0, 0, 0)) and quotes are kept
+func SplitValue(value string) []string {
+	var result []string
+	depth, start := 0, -1
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+
+		if depth == 0 && isSpace(c) {
+			if start >= 0 {
+				result = append(result, value[start:i])
+				start = -1
+			}
+
+			continue
+		}
+
+		if start < 0 {
+			start = i
+		}
+
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case '"', '\'':
+			i = quoteEnd(value, i)
+		}
+	}
+
+	if start >= 0 {
+		result = append(result, value[start:])
+	}
+
+	return result
+}
+
+// ExpandSides Expands 1-4 values of box shorthand (margin, border-width...) to top, right, bottom and left values.
+// Returns false for other number of values
+func ExpandSides(values []string) ([4]string, bool) {
+	switch len(values) {
+	case 1:
+		return [4]string{values[0], values[0], values[0], values[0]}, true
+	case 2:
+		return [4]string{values[0], values[1], values[0], values[1]}, true
+	case 3:
+		return [4]string{values[0], values[1], values[2], values[1]}, true
+	case 4:
+		return [4]string{values[0], values[1], values[2], values[3]}, true
+	}
+
+	return [4]string{}, false
+}
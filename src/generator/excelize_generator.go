@@ -1,10 +1,28 @@
 package generator
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/360EntSecGroup-Skylar/excelize/v2"
 	"github.com/icewind666/html-to-excel-renderer/src/types"
 	log "github.com/sirupsen/logrus"
+	"math"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// MaxSheetNameLength Max length of excel sheet name
+const MaxSheetNameLength = 31
+
+// Column widths (in characters) and row heights (in points) to pixels, like excel does with default font
+const (
+	maxDigitWidth   = 7.0  // pixels of character
+	colWidthPadding = 5.0  // pixels of column
+	pointsPerPixel  = 0.75 // 96 pixels per inch
+
+	defaultColWidthPixels = 64.0 // width of columns without width set by generator
 )
 
 // ExcelizeGenerator struct for handling state of excel generation processing
@@ -14,6 +32,9 @@ type ExcelizeGenerator struct {
 	CurrentSheet string
 	CurrentCol   int
 	CurrentRow   int
+
+	colWidths  map[string]map[int]float64 // widths (in characters) of columns set by generator by sheet name
+	rowHeights map[string]map[int]bool    // rows with height set by generator by sheet name
 }
 
 
@@ -36,8 +57,17 @@ func (x *ExcelizeGenerator) Open(filename string) bool {
 	return true
 }
 
+// Save Saves workbook to file. "-" writes workbook to stdout
 func (x *ExcelizeGenerator) Save(filename string) {
-	err := x.OpenedFile.SaveAs(filename)
+	var err error
+	x.sharePictureRelationships()
+	x.autoRowHeights()
+
+	if filename == types.StdioFilename {
+		err = x.OpenedFile.Write(os.Stdout)
+	} else {
+		err = x.OpenedFile.SaveAs(filename)
+	}
 
 	if err != nil {
 		log.WithError(err).Fatalln("Cant save excel file!")
@@ -51,9 +81,79 @@ func (x *ExcelizeGenerator) AddSheet(sheetName string) {
 	x.CurrentSheet = sheetName
 }
 
+// ReserveSheet Creates sheet with name not used in workbook: name or name with number suffix.
+// Current sheet is not changed. Returns name of created sheet
+func (x *ExcelizeGenerator) ReserveSheet(name string) string {
+	uniqueName := truncateSheetName(name, MaxSheetNameLength)
+
+	for i := 2; x.HasSheet(uniqueName); i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		uniqueName = truncateSheetName(name, MaxSheetNameLength-len(suffix)) + suffix
+	}
+
+	x.OpenedFile.NewSheet(uniqueName)
+	return uniqueName
+}
+
+// truncateSheetName Cuts sheet name to length characters
+func truncateSheetName(name string, length int) string {
+	runes := []rune(name)
+
+	if len(runes) > length {
+		return string(runes[:length])
+	}
+
+	return name
+}
+
+// SetCellSheetLink Sets current cell value to sheet name linked to the first cell of the sheet
+func (x *ExcelizeGenerator) SetCellSheetLink(sheetName string) {
+	x.SetCellValue(sheetName)
+	x.SetCellLocationLink(sheetName, "A1")
+}
+
+// SetCellLocationLink Links current cell to cell of sheet in workbook. Cell value is not changed
+func (x *ExcelizeGenerator) SetCellLocationLink(sheetName string, cell string) {
+	cellName, _ := excelize.CoordinatesToCellName(x.CurrentCol, x.CurrentRow)
+	location := fmt.Sprintf("'%s'!%s", strings.ReplaceAll(sheetName, "'", "''"), cell)
+
+	if err := x.OpenedFile.SetCellHyperLink(x.CurrentSheet, cellName, location, "Location"); err != nil {
+		log.WithError(err).Error("Cant set sheet link")
+	}
+}
+
+// SetCellExternalLink Links current cell to url (https:, mailto:...). Cell value is not changed
+func (x *ExcelizeGenerator) SetCellExternalLink(url string) {
+	cellName, _ := excelize.CoordinatesToCellName(x.CurrentCol, x.CurrentRow)
+
+	if err := x.OpenedFile.SetCellHyperLink(x.CurrentSheet, cellName, url, "External"); err != nil {
+		log.WithError(err).Error("Cant set cell link")
+	}
+}
+
+// HasSheet Checks workbook has sheet with given name
+func (x *ExcelizeGenerator) HasSheet(sheetName string) bool {
+	return x.OpenedFile.GetSheetIndex(sheetName) != -1
+}
+
+// SelectSheet Sets existing sheet as current
+func (x *ExcelizeGenerator) SelectSheet(sheetName string) {
+	x.CurrentSheet = sheetName
+}
+
 // SetSheetName Renames sheet
 func (x *ExcelizeGenerator) SetSheetName(oldSheetName string, sheetName string) {
 	x.OpenedFile.SetSheetName(oldSheetName, sheetName)
+
+	if widths, ok := x.colWidths[oldSheetName]; ok {
+		delete(x.colWidths, oldSheetName)
+		x.colWidths[sheetName] = widths
+	}
+
+	if heights, ok := x.rowHeights[oldSheetName]; ok {
+		delete(x.rowHeights, oldSheetName)
+		x.rowHeights[sheetName] = heights
+	}
 	x.CurrentSheet = sheetName
 }
 
@@ -90,38 +190,69 @@ func (x *ExcelizeGenerator) GetCell() string {
 	return fmt.Sprintf("%d%d", x.CurrentCol, x.CurrentRow)
 }
 
-func (x *ExcelizeGenerator) SetRowHeight(rowHeight int) {
-	err := x.OpenedFile.SetRowHeight(x.CurrentSheet, x.CurrentRow, float64(rowHeight))
+// SetRowHeight Sets fixed height of current row in points
+func (x *ExcelizeGenerator) SetRowHeight(rowHeight float64) {
+	err := x.OpenedFile.SetRowHeight(x.CurrentSheet, x.CurrentRow, rowHeight)
 
 	if err != nil {
 		log.WithError(err).Error("Cant set row height")
+		return
+	}
+
+	if x.rowHeights == nil {
+		x.rowHeights = make(map[string]map[int]bool)
 	}
 
+	if x.rowHeights[x.CurrentSheet] == nil {
+		x.rowHeights[x.CurrentSheet] = make(map[int]bool)
+	}
+
+	x.rowHeights[x.CurrentSheet][x.CurrentRow] = true
 }
 
 func (x ExcelizeGenerator) GetCoords() (string,error) {
 	return excelize.CoordinatesToCellName(x.CurrentCol, x.CurrentRow)
 }
 
-// SetColspan Sets cospan style for column. Current column indexes are taken from
-// CurrentRow & CurrentCol of  ExcelizeGenerator instance
-func (x *ExcelizeGenerator) SetColspan(endColumnNumber int) {
-	endColumnIndex := x.CurrentCol + endColumnNumber-1
-	endColumnName,err := excelize.CoordinatesToCellName(endColumnIndex, x.CurrentRow)
+// MergeSpan Merges cells spanned by current cell. Current cell indexes are taken from
+// CurrentRow & CurrentCol of ExcelizeGenerator instance
+func (x *ExcelizeGenerator) MergeSpan(colspan int, rowspan int) {
+	if colspan <= 1 && rowspan <= 1 {
+		return
+	}
+
+	cellFrom, cellTo, err := x.spanRange(colspan, rowspan)
 
 	if err != nil {
 		log.WithError(err).Error("Cant get current cell coordinates")
+		return
 	}
 
-	currentCellName,err := excelize.CoordinatesToCellName(x.CurrentCol, x.CurrentRow)
+	err = x.OpenedFile.MergeCell(x.CurrentSheet, cellFrom, cellTo)
+
 	if err != nil {
-		log.WithError(err).Error("Cant get current cell coordinates")
+		log.WithError(err).Error("Cant merge cells")
 	}
+}
+
+// spanRange Returns first and last cell names of range spanned by current cell
+func (x *ExcelizeGenerator) spanRange(colspan int, rowspan int) (string, string, error) {
+	if colspan < 1 {
+		colspan = 1
+	}
+
+	if rowspan < 1 {
+		rowspan = 1
+	}
+
+	cellFrom, err := excelize.CoordinatesToCellName(x.CurrentCol, x.CurrentRow)
 
-	err = x.OpenedFile.MergeCell(x.CurrentSheet, currentCellName, endColumnName)
 	if err != nil {
-		log.WithError(err).Error("Cant merge cells")
+		return "", "", err
 	}
+
+	cellTo, err := excelize.CoordinatesToCellName(x.CurrentCol+colspan-1, x.CurrentRow+rowspan-1)
+	return cellFrom, cellTo, err
 }
 
 
@@ -153,49 +284,15 @@ func (x *ExcelizeGenerator) getCellBorders(style *types.HtmlStyle) string{
 	return BordersToExcelizeString(style)
 }
 
-func (x *ExcelizeGenerator) ApplyBordersRange(style *types.HtmlStyle) {
-	styleJson := fmt.Sprintf(`
-				{
-					"font": %s,
-				
-					"border": %s,				
-				
-					"alignment": %s,
-
-					"fill": %s
-				}`,
-		x.getCellFont(style),
-		x.getCellBorders(style),
-		x.getCellAlignment(style),
-		x.getCellColor(style),
-	)
-
-	newStyle, err := x.OpenedFile.NewStyle(styleJson)
-
-	if err != nil {
-		log.WithError(err).Fatalln("Cant create new style in Excel sheet")
-	}
-
-	cellFrom,err := excelize.CoordinatesToCellName(x.CurrentCol, x.CurrentRow)
-	cellTo,err := excelize.CoordinatesToCellName(x.CurrentCol + style.Colspan -1, x.CurrentRow)
-
-	log.Infoln("cell from ", x.CurrentCol)
-	log.Infoln("cell to ", x.CurrentCol + style.Colspan)
-
-	err = x.OpenedFile.SetCellStyle(x.CurrentSheet, cellFrom, cellTo, newStyle)
-
-	if err != nil {
-		log.WithError(err).Error("Cant set style")
-	}
-
-	if style.Colspan > 1 {
-		x.SetColspan(style.Colspan)
-	}
-
-
+// ApplyCellStyle Sets style of current cell. Cells spanned by current cell (style Colspan and Rowspan)
+// get the same style, so borders of merged cell are drawn, and are merged
+func (x *ExcelizeGenerator) ApplyCellStyle(style *types.HtmlStyle) {
+	x.ApplyRangeStyle(style)
+	x.MergeSpan(style.Colspan, style.Rowspan)
 }
 
-func (x *ExcelizeGenerator) ApplyCellStyle(style *types.HtmlStyle) {
+// ApplyRangeStyle Sets style of current cell and cells spanned by it (style Colspan and Rowspan) without merging them
+func (x *ExcelizeGenerator) ApplyRangeStyle(style *types.HtmlStyle) {
 	styleJson := fmt.Sprintf(`
 				{
 					"font": %s,
@@ -218,29 +315,24 @@ func (x *ExcelizeGenerator) ApplyCellStyle(style *types.HtmlStyle) {
 		log.WithError(err).Fatalln("Cant create new style in Excel sheet")
 	}
 
-	cell,err := excelize.CoordinatesToCellName(x.CurrentCol, x.CurrentRow)
+	cellFrom, cellTo, err := x.spanRange(style.Colspan, style.Rowspan)
 
 	if err != nil {
 		log.WithError(err).Error("Cant get current cell coordinates")
 	}
 
-	err = x.OpenedFile.SetCellStyle(x.CurrentSheet, cell, cell, newStyle)
+	err = x.OpenedFile.SetCellStyle(x.CurrentSheet, cellFrom, cellTo, newStyle)
 
 	if err != nil {
 		log.WithError(err).Error("Cant set style")
 	}
-
-	if style.Colspan > 1 {
-		x.SetColspan(style.Colspan)
-	}
-
 }
 
+// ApplyRowStyle Sets height of current row when style has height. Rows without height keep auto height,
+// so wrapped multi-line cells are shown whole
 func (x *ExcelizeGenerator) ApplyRowStyle(style *types.HtmlStyle) {
 	if style.Height > 0 {
-		x.SetRowHeight(int(style.Height))
-	} else {
-		x.SetRowHeight(15) // default
+		x.SetRowHeight(style.Height)
 	}
 }
 
@@ -261,6 +353,8 @@ func (x *ExcelizeGenerator) ApplyColumnStyle(style *types.HtmlStyle) {
 		err := x.OpenedFile.SetColWidth(x.CurrentSheet, colName, colName, style.Width)
 		if err != nil {
 			log.WithError(err).Error("Cant set column width")
+		} else {
+			x.setColWidth(x.CurrentCol, style.Width)
 		}
 
 	}
@@ -279,6 +373,193 @@ func (x *ExcelizeGenerator) SetCellValue(value string) {
 	}
 }
 
+// AddCellPicture Inserts picture to current cell. Picture is shrunk to fit range of current cell when autofit,
+// otherwise it is scaled by xScale and yScale of its size in pixels. Identical pictures share one media part
+// and, when workbook is saved, one relationship of sheet drawing
+func (x *ExcelizeGenerator) AddCellPicture(picture []byte, extension string, xScale float64, yScale float64,
+	autofit bool) error {
+	cellName, err := excelize.CoordinatesToCellName(x.CurrentCol, x.CurrentRow)
+
+	if err != nil {
+		return err
+	}
+
+	format, _ := json.Marshal(map[string]interface{}{
+		"autofit":           autofit,
+		"x_scale":           xScale,
+		"y_scale":           yScale,
+		"lock_aspect_ratio": true,
+		"positioning":       "oneCell",
+	})
+
+	return x.OpenedFile.AddPictureFromBytes(x.CurrentSheet, cellName, string(format), "", extension, picture)
+}
+
+// sharePictureRelationships Points pictures of drawings to one relationship per media part. Excelize stores
+// identical pictures once, but adds relationship to media for every picture. Called once before saving
+func (x *ExcelizeGenerator) sharePictureRelationships() {
+	for path, drawing := range x.OpenedFile.Drawings {
+		rels := x.OpenedFile.Relationships[strings.Replace(path, "xl/drawings/", "xl/drawings/_rels/", 1)+".rels"]
+
+		if drawing == nil || rels == nil {
+			continue
+		}
+
+		first := make(map[string]string) // first relationship of media
+		shared := make(map[string]string) // duplicate relationship -> first relationship
+		kept := rels.Relationships[:0]
+
+		for _, rel := range rels.Relationships {
+			if rel.Type != excelize.SourceRelationshipImage {
+				kept = append(kept, rel)
+			} else if id, ok := first[rel.Target]; ok {
+				shared[rel.ID] = id
+			} else {
+				first[rel.Target] = rel.ID
+				kept = append(kept, rel)
+			}
+		}
+
+		if len(shared) == 0 {
+			continue
+		}
+
+		rels.Relationships = kept
+
+		for _, anchor := range drawing.TwoCellAnchor { // excelize anchors pictures by two cells
+			if anchor.Pic == nil {
+				continue
+			}
+
+			if id, ok := shared[anchor.Pic.BlipFill.Blip.Embed]; ok {
+				anchor.Pic.BlipFill.Blip.Embed = id
+			}
+		}
+	}
+}
+
+// autoRowHeights Makes rows without height set by generator auto height. Excelize creates rows with fixed
+// default height, so wrapped multi-line cells would show their first line only. Called once before saving
+func (x *ExcelizeGenerator) autoRowHeights() {
+	rels := x.OpenedFile.Relationships["xl/_rels/workbook.xml.rels"]
+
+	if x.OpenedFile.WorkBook == nil || rels == nil {
+		return
+	}
+
+	for _, sheet := range x.OpenedFile.WorkBook.Sheets.Sheet {
+		for _, rel := range rels.Relationships {
+			if rel.ID != sheet.ID {
+				continue
+			}
+
+			ws := x.OpenedFile.Sheet["xl/worksheets/"+path.Base(rel.Target)]
+
+			if ws == nil || ws.SheetFormatPr == nil {
+				continue // sheet is not changed or its rows have no default height
+			}
+
+			for i := range ws.SheetData.Row {
+				row := &ws.SheetData.Row[i]
+
+				if row.CustomHeight && row.Ht == ws.SheetFormatPr.DefaultRowHeight && !x.rowHeights[sheet.Name][row.R] {
+					row.Ht, row.CustomHeight = 0, false
+				}
+			}
+		}
+	}
+}
+
+// setColWidth Records width (in characters) of column of current sheet set by generator
+func (x *ExcelizeGenerator) setColWidth(col int, width float64) {
+	if x.colWidths == nil {
+		x.colWidths = make(map[string]map[int]float64)
+	}
+
+	if x.colWidths[x.CurrentSheet] == nil {
+		x.colWidths[x.CurrentSheet] = make(map[int]float64)
+	}
+
+	x.colWidths[x.CurrentSheet][col] = width
+}
+
+// CellRangePixels Returns width and height in pixels of colspan x rowspan cells range starting at current cell.
+// Columns without width set by generator have default width
+func (x *ExcelizeGenerator) CellRangePixels(colspan int, rowspan int) (float64, float64) {
+	width, height := 0.0, 0.0
+
+	for col := x.CurrentCol; col < x.CurrentCol+colspan; col++ {
+		if colWidth, ok := x.colWidths[x.CurrentSheet][col]; ok {
+			width += math.Ceil(colWidth*maxDigitWidth + colWidthPadding)
+		} else {
+			width += defaultColWidthPixels
+		}
+	}
+
+	for row := x.CurrentRow; row < x.CurrentRow+rowspan; row++ {
+		rowHeight, _ := x.OpenedFile.GetRowHeight(x.CurrentSheet, row)
+		height += rowHeight / pointsPerPixel
+	}
+
+	return width, height
+}
+
+// RichTextRun Text run with its own font and vertical alignment: superscript, subscript or baseline when empty
+type RichTextRun struct {
+	excelize.RichTextRun
+	VertAlign string
+}
+
+// Vertical alignments of rich text runs
+const (
+	VertAlignSuperscript = "superscript"
+	VertAlignSubscript   = "subscript"
+)
+
+// SetCellRichText Sets text runs with their own fonts to current cell
+func (x *ExcelizeGenerator) SetCellRichText(runs []RichTextRun) {
+	cellName, _ := excelize.CoordinatesToCellName(x.CurrentCol, x.CurrentRow)
+	textRuns := make([]excelize.RichTextRun, len(runs))
+
+	for i, run := range runs {
+		textRuns[i] = run.RichTextRun
+	}
+
+	err := x.OpenedFile.SetCellRichText(x.CurrentSheet, cellName, textRuns)
+
+	if err != nil {
+		log.WithError(err).Error("Cant set rich text to cell")
+		return
+	}
+
+	x.setRunsVertAlign(runs)
+}
+
+// setRunsVertAlign Sets vertical alignment of runs of rich text just set. Excelize writes run properties
+// with vertical alignment, but its fonts don't set it, so it is set on properties of the shared string
+// of rich text. Type of the property isn't exported, so it is made by reflection
+func (x *ExcelizeGenerator) setRunsVertAlign(runs []RichTextRun) {
+	sst := x.OpenedFile.SharedStrings
+
+	if sst == nil || len(sst.SI) == 0 {
+		return
+	}
+
+	textRuns := sst.SI[len(sst.SI)-1].R // SetCellRichText appends shared string of rich text
+
+	for i, run := range runs {
+		if run.VertAlign == "" || i >= len(textRuns) || textRuns[i].RPr == nil {
+			continue // excelize writes run properties of runs with font only
+		}
+
+		vertAlign := reflect.ValueOf(&textRuns[i].RPr.VertAlign).Elem()
+		value := reflect.New(vertAlign.Type().Elem())
+		align := run.VertAlign
+		value.Elem().FieldByName("Val").Set(reflect.ValueOf(&align))
+		vertAlign.Set(value)
+	}
+}
+
 func (x *ExcelizeGenerator) SetCellFloatValue(value float64) {
 	cellName,_ := excelize.CoordinatesToCellName(x.CurrentCol,x.CurrentRow)
 	err := x.OpenedFile.SetCellFloat(x.CurrentSheet, cellName, value, 3, 64)
@@ -303,13 +584,29 @@ func (x *ExcelizeGenerator) SetCellIntValue(value int) {
 	}
 }
 
-func FontToExcelizeString(style *types.HtmlStyle) string  {
-	// making font style json for excel
-	isBold := "false"
-	if style.IsBold {
-		isBold = "true"
+// FontToExcelizeString Returns excelize font json of style
+func FontToExcelizeString(style *types.HtmlStyle) string {
+	font, err := json.Marshal(ExcelizeFont(style))
+
+	if err != nil {
+		log.WithError(err).Error("Cant make font style")
+		return "{}"
+	}
+
+	return string(font)
+}
+
+// ExcelizeFont Returns excelize font of style. Empty family, size and color are excel defaults
+func ExcelizeFont(style *types.HtmlStyle) *excelize.Font {
+	return &excelize.Font{
+		Bold:      style.IsBold,
+		Italic:    style.IsItalic,
+		Underline: style.Underline,
+		Family:    style.FontFamily,
+		Size:      style.FontSize,
+		Strike:    style.IsStrike,
+		Color:     style.Color,
 	}
-	return fmt.Sprintf(`{"bold": %s,"size":%f}`, isBold, style.FontSize)
 }
 
 func AlignmentToExcelizeString(style *types.HtmlStyle) string {
@@ -327,29 +624,62 @@ func ColorToExcelizeString(style *types.HtmlStyle) string {
 	return fmt.Sprintf(`{"type": "pattern","color":["%s"],"pattern":1}`, "#ffffff")
 }
 
+// BordersToExcelizeString Returns excelize borders json of visible cell borders. Borders without color
+// have font color
 func BordersToExcelizeString(style *types.HtmlStyle) string {
-	if style.BorderStyle {
-		return `[
-			{
-				"type": "left",
-				"style": 1,
-				"color":"#000000"
-			},
-			{
-				"type": "top",
-				"style": 1,
-				"color":"#000000"
-			},
-			{
-				"type": "bottom",
-				"style": 1,
-				"color":"#000000"
-			},
-			{
-				"type": "right",
-				"style": 1,
-				"color":"#000000"
-			}]`
-	}
-	return "[]"
+	sides := []string{"top", "right", "bottom", "left"} // order of style.Borders
+	var borders []string
+
+	for i, border := range style.Borders {
+		if !border.IsVisible() {
+			continue
+		}
+
+		color := border.Color
+
+		if color == "" {
+			color = style.Color
+		}
+
+		if color == "" {
+			color = "#000000"
+		}
+
+		borders = append(borders, fmt.Sprintf(`{"type": "%s","style": %d,"color":"%s"}`,
+			sides[i], excelBorderStyle(border), color))
+	}
+
+	return "[" + strings.Join(borders, ",") + "]"
+}
+
+// excelBorderStyle Returns excelize border style index of css border style and width:
+// hair (<1px), thin (<2px), medium (<4px) or thick lines, dashed, dotted and double lines
+func excelBorderStyle(border types.Border) int {
+	switch border.Style {
+	case "double":
+		return 6
+	case "dotted":
+		if border.Width >= 2 {
+			return 12 // medium dash dot dot
+		}
+
+		return 4
+	case "dashed":
+		if border.Width >= 2 {
+			return 8
+		}
+
+		return 3
+	}
+
+	switch {
+	case border.Width < 1:
+		return 7
+	case border.Width < 2:
+		return 1
+	case border.Width < 4:
+		return 2
+	}
+
+	return 5
 }
\ No newline at end of file
@@ -0,0 +1,287 @@
+package generator
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/icewind666/html-to-excel-renderer/src/types"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCellRangePixelsUsesColumnWidthsSetByGenerator(t *testing.T) {
+	generator := &ExcelizeGenerator{}
+	generator.Create()
+	generator.SetSheetName("Sheet1", "Report")
+
+	// 64 characters wide column must not be taken for column without width (64 pixels)
+	generator.CurrentCol, generator.CurrentRow = 2, 1
+	generator.ApplyColumnStyle(&types.HtmlStyle{Width: 64})
+	generator.CurrentCol = 3
+	generator.ApplyColumnStyle(&types.HtmlStyle{Width: 10})
+
+	generator.CurrentCol = 1
+	width, height := generator.CellRangePixels(4, 2)
+
+	if want := 64 + (64*7 + 5) + (10*7 + 5) + 64.0; width != want {
+		t.Errorf("width is %v pixels, want %v", width, want)
+	}
+
+	if height != 40 {
+		t.Errorf("height is %v pixels, want 40", height)
+	}
+
+	generator.AddSheet("Other")
+	generator.CurrentCol, generator.CurrentRow = 2, 1
+
+	if width, _ := generator.CellRangePixels(1, 1); width != defaultColWidthPixels {
+		t.Errorf("width of column without width on other sheet is %v pixels, want %v", width, defaultColWidthPixels)
+	}
+}
+
+// pngPicture Returns png picture of one pixel of gray level
+func pngPicture(t *testing.T, gray uint8) []byte {
+	var picture bytes.Buffer
+	img := image.NewGray(image.Rect(0, 0, 1, 1))
+	img.Pix[0] = gray
+
+	if err := png.Encode(&picture, img); err != nil {
+		t.Fatal(err)
+	}
+
+	return picture.Bytes()
+}
+
+func TestIdenticalPicturesShareMediaAndRelationship(t *testing.T) {
+	dir, err := ioutil.TempDir("", "generator")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	generator := &ExcelizeGenerator{}
+	generator.Create()
+	generator.SetSheetName("Sheet1", "First")
+	icon, other := pngPicture(t, 0), pngPicture(t, 255)
+
+	for row, picture := range [][]byte{icon, other, append([]byte(nil), icon...), icon} {
+		generator.CurrentCol, generator.CurrentRow = 1, row+1
+
+		if err := generator.AddCellPicture(picture, ".png", 1, 1, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	generator.AddSheet("Second")
+	generator.CurrentCol, generator.CurrentRow = 1, 1
+
+	if err := generator.AddCellPicture(icon, ".png", 1, 1, true); err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "pictures.xlsx")
+	generator.Save(filename)
+
+	workbook, err := zip.OpenReader(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer workbook.Close()
+
+	media, rels, embeds := 0, make(map[string][]string), make(map[string][]string)
+	embedRegexp := regexp.MustCompile(`r:embed="([^"]+)"`)
+	idRegexp := regexp.MustCompile(`Id="([^"]+)"[^>]*Type="` + regexp.QuoteMeta(excelize.SourceRelationshipImage) + `"`)
+
+	for _, file := range workbook.File {
+		content, err := readZipFile(file)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		switch {
+		case strings.HasPrefix(file.Name, "xl/media/"):
+			media++
+		case strings.HasPrefix(file.Name, "xl/drawings/_rels/"):
+			drawing := strings.TrimSuffix(strings.Replace(file.Name, "_rels/", "", 1), ".rels")
+
+			for _, match := range idRegexp.FindAllStringSubmatch(content, -1) {
+				rels[drawing] = append(rels[drawing], match[1])
+			}
+		case strings.HasPrefix(file.Name, "xl/drawings/"):
+			for _, match := range embedRegexp.FindAllStringSubmatch(content, -1) {
+				embeds[file.Name] = append(embeds[file.Name], match[1])
+			}
+		}
+	}
+
+	if media != 2 {
+		t.Errorf("%d media parts, want 2", media)
+	}
+
+	wantRels := map[string]int{"xl/drawings/drawing1.xml": 2, "xl/drawings/drawing2.xml": 1}
+	wantEmbeds := map[string]int{"xl/drawings/drawing1.xml": 4, "xl/drawings/drawing2.xml": 1}
+
+	for drawing, want := range wantRels {
+		if len(rels[drawing]) != want {
+			t.Errorf("%s has image relationships %v, want %d", drawing, rels[drawing], want)
+		}
+
+		if len(embeds[drawing]) != wantEmbeds[drawing] {
+			t.Errorf("%s has pictures %v, want %d", drawing, embeds[drawing], wantEmbeds[drawing])
+		}
+
+		for _, embed := range embeds[drawing] {
+			if !containsString(rels[drawing], embed) {
+				t.Errorf("%s picture refers to missing relationship %s", drawing, embed)
+			}
+		}
+	}
+
+	if first := embeds["xl/drawings/drawing1.xml"]; len(first) == 4 && (first[0] != first[2] || first[0] != first[3]) {
+		t.Errorf("identical pictures refer to relationships %v, want one", first)
+	}
+}
+
+// readZipFile Returns content of file of zip archive
+func readZipFile(file *zip.File) (string, error) {
+	reader, err := file.Open()
+
+	if err != nil {
+		return "", err
+	}
+
+	defer reader.Close()
+	content, err := ioutil.ReadAll(reader)
+	return string(content), err
+}
+
+// containsString Checks values contain value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestApplyRowStyleKeepsAutoHeight(t *testing.T) {
+	dir, err := ioutil.TempDir("", "generator")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	generator := &ExcelizeGenerator{}
+	generator.Create()
+	generator.SetSheetName("Sheet1", "Report")
+
+	for row, style := range []*types.HtmlStyle{{IsBold: true}, {Height: 30}, {Height: 15}} {
+		generator.CurrentCol, generator.CurrentRow = 1, row+1
+		generator.SetCellValue("line\nline")
+		generator.ApplyRowStyle(style)
+	}
+
+	filename := filepath.Join(dir, "rows.xlsx")
+	generator.Save(filename)
+
+	workbook, err := zip.OpenReader(filename)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer workbook.Close()
+
+	for _, file := range workbook.File {
+		if file.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+
+		sheet, err := readZipFile(file)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if regexp.MustCompile(`<row r="1"[^>]*(ht|customHeight)=`).MatchString(sheet) {
+			t.Error("row without height got fixed height")
+		}
+
+		for row, height := range map[int]string{2: "30", 3: "15"} {
+			if !regexp.MustCompile(`<row r="` + strconv.Itoa(row) + `"[^>]*ht="` + height + `"`).MatchString(sheet) {
+				t.Errorf("row %d has no height of %s points", row, height)
+			}
+		}
+	}
+}
+
+func TestSetCellRichTextWritesVerticalAlignment(t *testing.T) {
+	generator := &ExcelizeGenerator{}
+	generator.Create()
+	generator.CurrentSheet, generator.CurrentCol, generator.CurrentRow = "Sheet1", 1, 1
+	font := &excelize.Font{Family: "Calibri", Size: 11}
+
+	generator.SetCellRichText([]RichTextRun{
+		{RichTextRun: excelize.RichTextRun{Font: font, Text: "m"}},
+		{RichTextRun: excelize.RichTextRun{Font: font, Text: "2"}, VertAlign: VertAlignSuperscript},
+		{RichTextRun: excelize.RichTextRun{Font: font, Text: "x"}, VertAlign: VertAlignSubscript},
+	})
+
+	buffer, err := generator.OpenedFile.WriteToBuffer()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workbook, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range workbook.File {
+		if file.Name != "xl/sharedStrings.xml" {
+			continue
+		}
+
+		sharedStrings, err := readZipFile(file)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		runs := regexp.MustCompile(`<r>.*?</r>`).FindAllString(sharedStrings, -1)
+		want := []string{"", `<vertAlign val="superscript">`, `<vertAlign val="subscript">`}
+
+		if len(runs) != len(want) {
+			t.Fatalf("shared strings have runs %q, want %d runs", runs, len(want))
+		}
+
+		for i, run := range runs {
+			if hasAlign := strings.Contains(run, "<vertAlign"); hasAlign != (want[i] != "") ||
+				want[i] != "" && !strings.Contains(run, want[i]) {
+				t.Errorf("run %s, want vertical alignment %q", run, want[i])
+			}
+		}
+
+		return
+	}
+
+	t.Error("workbook has no shared strings")
+}
@@ -0,0 +1,115 @@
+package helpers
+
+import (
+	"fmt"
+)
+
+// inspectionTypes Human readable names for inspection types used by formatType
+var inspectionTypes = map[string]string{
+	"BEFORE":       "Предрейсовый",
+	"BEFORE_SHIFT": "Предсменный",
+	"LINE":         "Линейный",
+	"AFTER":        "Послерейсовый",
+	"AFTER_SHIFT":  "Послесменный",
+	"ALCO":         "Алкотестирование",
+	"PIRO":         "Контроль температуры",
+	"PREVENTION":   "Профилактический",
+}
+
+// FormatDate Formats date as dd-mm-yyyy in local time zone
+func FormatDate(date interface{}) string {
+	d, ok := toDate(date)
+
+	if !ok {
+		return "NaN-NaN-NaN"
+	}
+
+	return fmt.Sprintf("%02d-%02d-%d", d.Day(), int(d.Month()), d.Year())
+}
+
+// FormatDateOfBirth Formats date of birth as dd-mm-yyyy in local time zone
+func FormatDateOfBirth(date interface{}) string {
+	return FormatDate(date)
+}
+
+// FormatDateTime Formats date and time as d-m-yyyy and h:m on the next line. Values are not zero padded
+func FormatDateTime(dateTime interface{}) string {
+	d, ok := toDate(dateTime)
+
+	if !ok {
+		return "NaN-NaN-NaN \nNaN:NaN"
+	}
+
+	return fmt.Sprintf("%d-%d-%d \n%d:%d", d.Day(), int(d.Month()), d.Year(), d.Hour(), d.Minute())
+}
+
+// FormatGender Returns М for MALE and Ж for anything else
+func FormatGender(gender interface{}) string {
+	if isString(gender, "MALE") {
+		return "М"
+	}
+
+	return "Ж"
+}
+
+// FormatOrganization Returns organization name or empty string
+func FormatOrganization(org interface{}) interface{} {
+	if truthy(org) {
+		if name := property(org, "name"); truthy(name) {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// FormatPressure Formats systolic and diastolic pressure of medical data as "sys / dia".
+// Missing values are shown as dash
+func FormatPressure(meddata interface{}) string {
+	systolic := DashOrData(property(meddata, "systolicPressure"))
+	diastolic := DashOrData(property(meddata, "diastolicPressure"))
+
+	return fmt.Sprintf("%s / %s", toString(systolic), toString(diastolic))
+}
+
+// FormatResult Returns inspection result name
+func FormatResult(result interface{}) string {
+	if truthy(result) {
+		return "Допуск"
+	}
+
+	return "Не допуск"
+}
+
+// FormatStatus Returns activity status name
+func FormatStatus(status interface{}) string {
+	if truthy(status) {
+		return "Активен"
+	}
+
+	return "Не активен"
+}
+
+// FormatType Returns inspection type name
+func FormatType(inspectionType interface{}) string {
+	if name, ok := inspectionType.(string); ok {
+		if result, found := inspectionTypes[name]; found {
+			return result
+		}
+	}
+
+	return "Неизвестный"
+}
+
+// FormatComplains Returns whether there are complains. Dash for null
+func FormatComplains(complains interface{}) string {
+	if complains == nil {
+		return "-"
+	}
+
+	if truthy(complains) {
+		return "Есть"
+	}
+
+	return "Нет"
+}
@@ -0,0 +1,285 @@
+package helpers
+
+import (
+	"github.com/icewind666/html-to-excel-renderer/src/render"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// Expected values are outputs of javascript helpers of this directory called with the same arguments.
+// Dates without offset are parsed in local time zone, so expected values don't depend on TZ
+func TestHelpersMatchJavascript(t *testing.T) {
+	nan := math.NaN()
+
+	tests := []struct {
+		helper string
+		args   []interface{}
+		want   interface{}
+	}{
+		{"dashHelper", nil, "-"},
+		{"dashHelper", []interface{}{nil}, nil},
+		{"dashHelper", []interface{}{"0"}, "-"},
+		{"dashHelper", []interface{}{0.0}, "-"},
+		{"dashHelper", []interface{}{""}, ""},
+		{"dashHelper", []interface{}{"0.0"}, "0.0"},
+		{"dashHelper", []interface{}{5.0}, 5.0},
+		{"dashMgtHelper", []interface{}{"0/0"}, "-"},
+		{"dashMgtHelper", []interface{}{"0.0"}, "-"},
+		{"dashMgtHelper", []interface{}{nil}, nil},
+		{"dashMgtHelper", []interface{}{"120/80"}, "120/80"},
+		{"dashOrData", []interface{}{nil}, "-"},
+		{"dashOrData", []interface{}{""}, ""},
+		{"dashOrData", []interface{}{0.0}, 0.0},
+		{"alchoHelper", []interface{}{0.0, "0.0"}, "-"},
+		{"alchoHelper", []interface{}{"0", "0.0"}, "0.0"},
+		{"alchoHelper", []interface{}{1.0, "0.3"}, "Обнаружен"},
+		{"alchoHelper", []interface{}{1.0, "0.0"}, "0.0"},
+		{"alchoHelper", []interface{}{2.0, nil}, nil},
+		{"allowHelper", []interface{}{"Допущен"}, "Прошел"},
+		{"allowHelper", []interface{}{nil}, "Не прошел"},
+		{"allowHelper", []interface{}{""}, "Не прошел"},
+		{"faceIdNotFoundName", []interface{}{"Иван", "Иванов", "Иванович"}, "Иванов Иван Иванович"},
+		{"faceIdNotFoundName", []interface{}{"", "Иванов", "Иванович"}, "нет соответствия"},
+		{"faceIdNotFoundName", []interface{}{"Иван", nil, nil}, "null Иван null"},
+		{"ifnull", []interface{}{nil, "-"}, "-"},
+		{"ifnull", []interface{}{"", "-"}, "-"},
+		{"ifnull", []interface{}{0.0, "-"}, "-"},
+		{"ifnull", []interface{}{nan, "-"}, "-"},
+		{"ifnull", []interface{}{"0", "-"}, "0"},
+		{"inspectionTimeHelper", nil, "00:00"},
+		{"inspectionTimeHelper", []interface{}{"05"}, "05:00"},
+		{"inspectionTimeHelper", []interface{}{"05", "30"}, "05:30"},
+		{"inspectionTimeHelper", []interface{}{nil, nil}, "null:null"},
+		{"zeroIntHelper", []interface{}{nil}, "00"},
+		{"zeroIntHelper", []interface{}{""}, "00"},
+		{"zeroIntHelper", []interface{}{0.0}, "00"},
+		{"zeroIntHelper", []interface{}{nan}, "00"},
+		{"zeroIntHelper", []interface{}{5.0}, "05"},
+		{"zeroIntHelper", []interface{}{"7"}, "07"},
+		{"zeroIntHelper", []interface{}{"12"}, "12"},
+		{"zeroIntHelper", []interface{}{12.0}, 12.0},
+		{"zeroIntHelper", []interface{}{"abc"}, "abc"},
+		{"pressureHelper", []interface{}{"120/80", 80.0}, "120/80"},
+		{"pressureHelper", []interface{}{"120/80", 0.0}, "-"},
+		{"pressureHelper", []interface{}{"120/80", "0"}, "120/80"},
+		{"pressureHelper", []interface{}{nil, 80.0}, "-"},
+		{"pressureHelper", []interface{}{"120/80", nan}, "-"},
+		{"sleep", []interface{}{nil}, "-"},
+		{"sleep", []interface{}{true}, "более 8 часов"},
+		{"sleep", []interface{}{false}, "менее 8 часов"},
+		{"sleep", []interface{}{""}, "менее 8 часов"},
+		{"upper", []interface{}{"abc"}, "ABC"},
+		{"upper", []interface{}{nil}, nil},
+		{"upper", []interface{}{5.0}, 5.0},
+		{"formatComplains", []interface{}{nil}, "-"},
+		{"formatComplains", []interface{}{true}, "Есть"},
+		{"formatComplains", []interface{}{""}, "Нет"},
+		{"formatGender", []interface{}{"MALE"}, "М"},
+		{"formatGender", []interface{}{nil}, "Ж"},
+		{"formatGender", []interface{}{"FEMALE"}, "Ж"},
+		{"formatResult", []interface{}{true}, "Допуск"},
+		{"formatResult", []interface{}{""}, "Не допуск"},
+		{"formatResult", []interface{}{nan}, "Не допуск"},
+		{"formatStatus", []interface{}{1.0}, "Активен"},
+		{"formatStatus", []interface{}{0.0}, "Не активен"},
+		{"formatType", []interface{}{"BEFORE"}, "Предрейсовый"},
+		{"formatType", []interface{}{"PREVENTION"}, "Профилактический"},
+		{"formatType", []interface{}{nil}, "Неизвестный"},
+		{"formatType", []interface{}{"x"}, "Неизвестный"},
+		{"formatOrganization", []interface{}{map[string]interface{}{"name": "ООО"}}, "ООО"},
+		{"formatOrganization", []interface{}{nil}, ""},
+		{"formatOrganization", []interface{}{map[string]interface{}{}}, ""},
+		{"formatOrganization", []interface{}{map[string]interface{}{"name": ""}}, ""},
+		{"formatPressure", []interface{}{map[string]interface{}{"systolicPressure": 120.0, "diastolicPressure": 80.0}},
+			"120 / 80"},
+		{"formatPressure", []interface{}{map[string]interface{}{"systolicPressure": nil, "diastolicPressure": "80"}},
+			"- / 80"},
+		{"formatDate", []interface{}{"2021-03-05T10:20"}, "05-03-2021"},
+		{"formatDate", []interface{}{""}, "NaN-NaN-NaN"},
+		{"formatDate", []interface{}{"abc"}, "NaN-NaN-NaN"},
+		{"formatDate", []interface{}{nan}, "NaN-NaN-NaN"},
+		{"formatDate", []interface{}{nil}, "NaN-NaN-NaN"},
+		{"formatDateOfBirth", []interface{}{"1990-12-31T00:00"}, "31-12-1990"},
+		{"formatDateTime", []interface{}{"2021-03-05T09:05"}, "5-3-2021 \n9:5"},
+		{"formatDateTime", []interface{}{"abc"}, "NaN-NaN-NaN \nNaN:NaN"},
+		{"formatDateTime", []interface{}{nil}, "NaN-NaN-NaN \nNaN:NaN"},
+		{"key", []interface{}{map[string]interface{}{"a": 1.0}, "a"}, 1.0},
+		{"key", []interface{}{[]interface{}{"x", "y"}, 1.0}, "y"},
+		{"key", []interface{}{[]interface{}{"x", "y"}, "1"}, "y"},
+		{"key", []interface{}{map[string]interface{}{"a": 1.0}, "b"}, nil},
+		{"math", []interface{}{"1", "+", "2"}, 3.0},
+		{"math", []interface{}{"1.5abc", "*", "2"}, 3.0},
+		{"math", []interface{}{"abc", "+", 1.0}, "NaN"},
+		{"math", []interface{}{7.0, "%", 3.0}, 1.0},
+		{"math", []interface{}{1.0, "/", 0.0}, "Infinity"},
+		{"math", []interface{}{nil, "+", 1.0}, "NaN"},
+		{"math", []interface{}{1.0, "^", 1.0}, nil},
+		{"math", []interface{}{"", "-", 1.0}, "NaN"},
+		{"percentHelper", []interface{}{0.1234}, "12.34%"},
+		{"percentHelper", []interface{}{"0.5"}, "50.00%"},
+		{"percentHelper", []interface{}{nil}, "0.00%"},
+		{"percentHelper", []interface{}{""}, "0.00%"},
+		{"percentHelper", []interface{}{"abc"}, "NaN%"},
+		{"percentHelper", []interface{}{0.0005}, "0.05%"},
+		{"percentHelper", []interface{}{1.005}, "100.50%"},
+		{"isAfterBeforeSheet", []interface{}{"Предрейсовый осмотр"}, true},
+		{"isAfterBeforeSheet", []interface{}{"Прочее"}, false},
+	}
+
+	for _, test := range tests {
+		helper, ok := builtin[test.helper]
+
+		if !ok {
+			t.Fatalf("unknown helper %s", test.helper)
+		}
+
+		args := make([]reflect.Value, len(test.args))
+
+		for i := range test.args {
+			args[i] = reflect.ValueOf(&test.args[i]).Elem()
+		}
+
+		got := reflect.ValueOf(helper).Call(args)[0].Interface()
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s%v = %#v, want %#v", test.helper, test.args, got, test.want)
+		}
+	}
+}
+
+func TestToDateRejectsNil(t *testing.T) {
+	if date, ok := toDate(nil); ok {
+		t.Errorf("toDate(nil) = %v, want invalid date", date)
+	}
+}
+
+func TestHandlebarsHelpersFillJavascriptDefaults(t *testing.T) {
+	tests := []struct {
+		helper string
+		args   []interface{}
+		want   interface{}
+	}{
+		{"dashHelper", nil, "-"},
+		{"dashHelper", []interface{}{"0"}, "-"},
+		{"inspectionTimeHelper", nil, "00:00"},
+		{"inspectionTimeHelper", []interface{}{"7"}, "7:00"},
+		{"inspectionTimeHelper", []interface{}{"7", "15"}, "7:15"},
+	}
+
+	for _, test := range tests {
+		helper, ok := Get(nil, test.helper)
+
+		if !ok {
+			t.Fatalf("unknown helper %s", test.helper)
+		}
+
+		variadic, ok := helper.(render.VariadicHelper)
+
+		if !ok {
+			t.Fatalf("helper %s is %T, want variadic helper", test.helper, helper)
+		}
+
+		if got := variadic(test.args, nil); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s%v = %#v, want %#v", test.helper, test.args, got, test.want)
+		}
+	}
+}
+
+func TestHandlebarsHelpersTellMissingPathsFromNull(t *testing.T) {
+	engine := &render.HandlebarsEngine{Helpers: All(nil)}
+	data := map[string]interface{}{"note": nil, "zero": 0.0, "row": map[string]interface{}{"min": nil}}
+
+	// outputs of javascript helpers: undefined takes the default argument, null is kept
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{`{{dashHelper missing.path}}`, "-"},
+		{`{{dashHelper row.sec}}`, "-"},
+		{`{{dashHelper note}}`, ""},
+		{`{{dashHelper zero}}`, "-"},
+		{`{{inspectionTimeHelper missing "5"}}`, "00:5"},
+		{`{{inspectionTimeHelper row.min "5"}}`, "null:5"},
+	}
+
+	for _, test := range tests {
+		got, err := engine.Render("report.hbs", test.source, data)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != test.want {
+			t.Errorf("%s rendered %q, want %q", test.source, got, test.want)
+		}
+	}
+}
+
+func TestLocaleCurrencyMatchesAcrossEngines(t *testing.T) {
+	locale, err := ParseLocale("ru")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handlebars := &render.HandlebarsEngine{Helpers: All(locale)}
+	goTemplate := &render.GoTemplateEngine{Funcs: TemplateFuncs(locale)}
+	data := map[string]interface{}{"total": 1234.5}
+
+	tests := []struct {
+		handlebars string
+		goTemplate string
+		want       string
+	}{
+		{`{{localeCurrency total}}`, `{{ localeCurrency .total }}`, "1\u00a0234,50\u00a0₽"},
+		{`{{localeCurrency total currency="EUR"}}`, `{{ localeCurrency .total "EUR" }}`, "1\u00a0234,50\u00a0€"},
+		{`{{localeCurrency total currency="EUR" decimals=0}}`, `{{ localeCurrency .total "EUR" 0 }}`, "1\u00a0235\u00a0€"},
+	}
+
+	for _, test := range tests {
+		fromHandlebars, err := handlebars.Render("report.hbs", test.handlebars, data)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fromGoTemplate, err := goTemplate.Render("report.tmpl", test.goTemplate, data)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if fromHandlebars != test.want || fromGoTemplate != test.want {
+			t.Errorf("%s = %q, %s = %q, want %q", test.handlebars, fromHandlebars, test.goTemplate, fromGoTemplate,
+				test.want)
+		}
+	}
+}
+
+func TestTemplateFuncsTolerateMissingValues(t *testing.T) {
+	engine := &render.GoTemplateEngine{Funcs: TemplateFuncs(nil)}
+	data := map[string]interface{}{"name": "ann"}
+
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{`{{ upper .name }}`, "ANN"},
+		{`[{{ upper .missing }}]`, "[]"},
+		{`[{{ .missing | upper }}]`, "[]"},
+		{`{{ upper 5 }}`, "5"},
+	}
+
+	for _, test := range tests {
+		result, err := engine.Render("report.tmpl", test.source, data)
+
+		if err != nil {
+			t.Fatalf("%s: %v", test.source, err)
+		}
+
+		if result != test.want {
+			t.Errorf("%s = %q, want %q", test.source, result, test.want)
+		}
+	}
+}
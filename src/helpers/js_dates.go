@@ -0,0 +1,72 @@
+package helpers
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// isoDateLayouts Date-only ISO forms are parsed as UTC by javascript Date
+var isoDateLayouts = []string{
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// offsetLayouts Date-time forms with explicit offset
+var offsetLayouts = []string{
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04:05.999999999Z07:00",
+}
+
+// localLayouts Date-time forms without offset are parsed in local time zone by javascript Date
+var localLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	"01/02/2006 15:04:05",
+	"01/02/2006 15:04",
+	"01/02/2006",
+}
+
+// toDate Javascript new Date(value). Returns false for Invalid Date and nil, missing dates are not the epoch
+func toDate(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return time.Time{}, false
+		}
+		return time.Unix(0, int64(v)*int64(time.Millisecond)).In(time.Local), true
+	case int:
+		return time.Unix(0, int64(v)*int64(time.Millisecond)).In(time.Local), true
+	case string:
+		return parseDateString(strings.TrimSpace(v))
+	}
+
+	return time.Time{}, false
+}
+
+// parseDateString Parses date string in formats accepted by javascript Date
+func parseDateString(s string) (time.Time, bool) {
+	for _, layout := range isoDateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t.In(time.Local), true
+		}
+	}
+
+	for _, layout := range offsetLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.In(time.Local), true
+		}
+	}
+
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,242 @@
+package helpers
+
+import (
+	"github.com/icewind666/html-to-excel-renderer/src/render"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// parseFloatRegexp Longest numeric prefix accepted by javascript parseFloat
+var parseFloatRegexp = regexp.MustCompile(`^[+-]?(Infinity|(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?)`)
+
+// truthy Javascript truthiness of value
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	case int:
+		return v != 0
+	}
+
+	return true // objects and arrays, even empty ones
+}
+
+// isString Checks value is a string equal to s (javascript ===)
+func isString(value interface{}, s string) bool {
+	str, ok := value.(string)
+	return ok && str == s
+}
+
+// isNumber Checks value is a number equal to n (javascript ===)
+func isNumber(value interface{}, n float64) bool {
+	switch v := value.(type) {
+	case float64:
+		return v == n
+	case int:
+		return float64(v) == n
+	}
+
+	return false
+}
+
+// toNumber Javascript Number(value) conversion
+func toNumber(value interface{}) float64 {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case string:
+		s := strings.TrimSpace(v)
+
+		if s == "" {
+			return 0
+		}
+
+		switch s {
+		case "Infinity", "+Infinity":
+			return math.Inf(1)
+		case "-Infinity":
+			return math.Inf(-1)
+		}
+
+		if f, err := strconv.ParseFloat(s, 64); err == nil && !strings.ContainsAny(s, "xXnN_") {
+			return f
+		}
+	}
+
+	return math.NaN()
+}
+
+// parseFloat Javascript parseFloat(value) conversion
+func parseFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+
+	prefix := parseFloatRegexp.FindString(strings.TrimLeft(toString(value), " \t\n\r\v\f"))
+
+	if prefix == "" {
+		return math.NaN()
+	}
+
+	if strings.HasSuffix(prefix, "Infinity") {
+		if strings.HasPrefix(prefix, "-") {
+			return math.Inf(-1)
+		}
+		return math.Inf(1)
+	}
+
+	f, _ := strconv.ParseFloat(prefix, 64)
+	return f
+}
+
+// toString Javascript String(value) conversion
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return numberToString(v)
+	case int:
+		return strconv.Itoa(v)
+	case []interface{}:
+		parts := make([]string, len(v))
+
+		for i, item := range v {
+			if item != nil {
+				parts[i] = toString(item)
+			}
+		}
+
+		return strings.Join(parts, ",")
+	case map[string]interface{}:
+		return "[object Object]"
+	}
+
+	return ""
+}
+
+// numberToString Javascript Number.prototype.toString()
+func numberToString(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	case f == 0:
+		return "0"
+	}
+
+	abs := math.Abs(f)
+
+	if abs >= 1e21 || abs < 1e-6 {
+		// javascript does not pad exponent: 1e-7, 1.5e+21
+		s := strconv.FormatFloat(f, 'e', -1, 64)
+		mantissa, exponent := s[:strings.Index(s, "e")], s[strings.Index(s, "e")+1:]
+		sign := exponent[:1]
+		exponent = strings.TrimLeft(exponent[1:], "0")
+
+		return mantissa + "e" + sign + exponent
+	}
+
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// toFixed Javascript Number.prototype.toFixed(digits). Ties are rounded away from zero
+// using exact binary value of f, not its shortest decimal representation
+func toFixed(f float64, digits int) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) || math.Abs(f) >= 1e21 {
+		return numberToString(f)
+	}
+
+	sign := ""
+
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+
+	// 1100 digits hold exact decimal expansion of any double
+	exact := strconv.FormatFloat(f, 'f', 1100, 64)
+	point := strings.Index(exact, ".")
+	kept := []byte(exact[:point] + exact[point+1:point+1+digits])
+
+	if exact[point+1+digits] >= '5' {
+		i := len(kept) - 1
+
+		for ; i >= 0 && kept[i] == '9'; i-- {
+			kept[i] = '0'
+		}
+
+		if i < 0 {
+			kept = append([]byte{'1'}, kept...)
+		} else {
+			kept[i]++
+		}
+	}
+
+	intLen := len(kept) - digits
+	result := string(kept[:intLen])
+
+	if digits > 0 {
+		result += "." + string(kept[intLen:])
+	}
+
+	return sign + result
+}
+
+// property Returns obj[name] for json objects and arrays
+func property(obj interface{}, name interface{}) interface{} {
+	switch o := obj.(type) {
+	case map[string]interface{}:
+		return o[toString(name)]
+	case []interface{}:
+		index := toNumber(name)
+
+		if index >= 0 && index < float64(len(o)) && index == math.Trunc(index) {
+			return o[int(index)]
+		}
+	}
+
+	return nil
+}
+
+// includes Javascript String.prototype.includes
+func includes(value interface{}, search string) bool {
+	return strings.Contains(toString(value), search)
+}
+
+// argOrDefault Returns argument i of helper or default of javascript parameter when argument is omitted
+// or undefined
+func argOrDefault(args []interface{}, i int, def interface{}) interface{} {
+	if i < len(args) && args[i] != render.Undefined {
+		return args[i]
+	}
+
+	return def
+}
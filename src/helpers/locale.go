@@ -0,0 +1,265 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale Locale used when --locale is not set
+const DefaultLocale = "en"
+
+// Plural categories of CLDR plural rules
+const (
+	PluralOne   = "one"
+	PluralFew   = "few"
+	PluralMany  = "many"
+	PluralOther = "other"
+)
+
+// Locale Date, number and plural formatting rules of a language
+type Locale struct {
+	Name string
+
+	DateFormats       map[string]string // short, medium, long, full date patterns
+	TimeFormats       map[string]string // short, medium, long time patterns
+	DateTimeSeparator string
+
+	Months           []string // month names in dates (genitive case for slavic languages)
+	MonthsStandalone []string // month names without day
+	ShortMonths      []string
+	Weekdays         []string // from Sunday
+	ShortWeekdays    []string
+	DayPeriods       [2]string // AM, PM
+
+	DecimalSeparator string
+	GroupSeparator   string
+	PercentPattern   string // # is replaced by number
+	CurrencyPattern  string // # is replaced by number, ¤ by currency symbol
+	Currency         string // default currency code
+
+	PluralCategories []string // categories in order of forms given to plural helper
+	pluralRule       func(i int64, v int) string
+}
+
+// currencySymbols Currency symbols by ISO 4217 code. Other currencies are printed with code
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"RUB": "₽",
+	"UAH": "₴",
+	"KZT": "₸",
+	"BYN": "Br",
+	"JPY": "¥",
+	"CNY": "¥",
+}
+
+// currencyDecimals Number of fraction digits for currencies without cents
+var currencyDecimals = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+}
+
+var locales = map[string]*Locale{
+	"en": {
+		Name:              "en",
+		DateFormats:       map[string]string{"short": "MM/dd/yyyy", "medium": "MMM d, yyyy", "long": "MMMM d, yyyy", "full": "EEEE, MMMM d, yyyy"},
+		TimeFormats:       map[string]string{"short": "h:mm a", "medium": "h:mm:ss a", "long": "h:mm:ss a z"},
+		DateTimeSeparator: ", ",
+		Months: []string{"January", "February", "March", "April", "May", "June",
+			"July", "August", "September", "October", "November", "December"},
+		MonthsStandalone: []string{"January", "February", "March", "April", "May", "June",
+			"July", "August", "September", "October", "November", "December"},
+		ShortMonths:      []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		Weekdays:         []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		ShortWeekdays:    []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		DayPeriods:       [2]string{"AM", "PM"},
+		DecimalSeparator: ".",
+		GroupSeparator:   ",",
+		PercentPattern:   "#%",
+		CurrencyPattern:  "¤#",
+		Currency:         "USD",
+		PluralCategories: []string{PluralOne, PluralOther},
+		pluralRule:       oneOtherPluralRule,
+	},
+	"ru": {
+		Name:              "ru",
+		DateFormats:       map[string]string{"short": "dd.MM.yyyy", "medium": "d MMM yyyy 'г.'", "long": "d MMMM yyyy 'г.'", "full": "EEEE, d MMMM yyyy 'г.'"},
+		TimeFormats:       map[string]string{"short": "HH:mm", "medium": "HH:mm:ss", "long": "HH:mm:ss z"},
+		DateTimeSeparator: ", ",
+		Months: []string{"января", "февраля", "марта", "апреля", "мая", "июня",
+			"июля", "августа", "сентября", "октября", "ноября", "декабря"},
+		MonthsStandalone: []string{"январь", "февраль", "март", "апрель", "май", "июнь",
+			"июль", "август", "сентябрь", "октябрь", "ноябрь", "декабрь"},
+		ShortMonths:      []string{"янв.", "февр.", "мар.", "апр.", "мая", "июн.", "июл.", "авг.", "сент.", "окт.", "нояб.", "дек."},
+		Weekdays:         []string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"},
+		ShortWeekdays:    []string{"вс", "пн", "вт", "ср", "чт", "пт", "сб"},
+		DayPeriods:       [2]string{"AM", "PM"},
+		DecimalSeparator: ",",
+		GroupSeparator:   "\u00a0",
+		PercentPattern:   "#\u00a0%",
+		CurrencyPattern:  "#\u00a0¤",
+		Currency:         "RUB",
+		PluralCategories: []string{PluralOne, PluralFew, PluralMany, PluralOther},
+		pluralRule:       slavicPluralRule,
+	},
+	"uk": {
+		Name:              "uk",
+		DateFormats:       map[string]string{"short": "dd.MM.yyyy", "medium": "d MMM yyyy 'р.'", "long": "d MMMM yyyy 'р.'", "full": "EEEE, d MMMM yyyy 'р.'"},
+		TimeFormats:       map[string]string{"short": "HH:mm", "medium": "HH:mm:ss", "long": "HH:mm:ss z"},
+		DateTimeSeparator: ", ",
+		Months: []string{"січня", "лютого", "березня", "квітня", "травня", "червня",
+			"липня", "серпня", "вересня", "жовтня", "листопада", "грудня"},
+		MonthsStandalone: []string{"січень", "лютий", "березень", "квітень", "травень", "червень",
+			"липень", "серпень", "вересень", "жовтень", "листопад", "грудень"},
+		ShortMonths:      []string{"січ.", "лют.", "бер.", "квіт.", "трав.", "черв.", "лип.", "серп.", "вер.", "жовт.", "лист.", "груд."},
+		Weekdays:         []string{"неділя", "понеділок", "вівторок", "середа", "четвер", "пʼятниця", "субота"},
+		ShortWeekdays:    []string{"нд", "пн", "вт", "ср", "чт", "пт", "сб"},
+		DayPeriods:       [2]string{"дп", "пп"},
+		DecimalSeparator: ",",
+		GroupSeparator:   "\u00a0",
+		PercentPattern:   "#%",
+		CurrencyPattern:  "#\u00a0¤",
+		Currency:         "UAH",
+		PluralCategories: []string{PluralOne, PluralFew, PluralMany, PluralOther},
+		pluralRule:       slavicPluralRule,
+	},
+	"de": {
+		Name:              "de",
+		DateFormats:       map[string]string{"short": "dd.MM.yyyy", "medium": "dd.MM.yyyy", "long": "d. MMMM yyyy", "full": "EEEE, d. MMMM yyyy"},
+		TimeFormats:       map[string]string{"short": "HH:mm", "medium": "HH:mm:ss", "long": "HH:mm:ss z"},
+		DateTimeSeparator: ", ",
+		Months: []string{"Januar", "Februar", "März", "April", "Mai", "Juni",
+			"Juli", "August", "September", "Oktober", "November", "Dezember"},
+		MonthsStandalone: []string{"Januar", "Februar", "März", "April", "Mai", "Juni",
+			"Juli", "August", "September", "Oktober", "November", "Dezember"},
+		ShortMonths:      []string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		Weekdays:         []string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		ShortWeekdays:    []string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+		DayPeriods:       [2]string{"AM", "PM"},
+		DecimalSeparator: ",",
+		GroupSeparator:   ".",
+		PercentPattern:   "#\u00a0%",
+		CurrencyPattern:  "#\u00a0¤",
+		Currency:         "EUR",
+		PluralCategories: []string{PluralOne, PluralOther},
+		pluralRule:       oneOtherPluralRule,
+	},
+	"fr": {
+		Name:              "fr",
+		DateFormats:       map[string]string{"short": "dd/MM/yyyy", "medium": "d MMM yyyy", "long": "d MMMM yyyy", "full": "EEEE d MMMM yyyy"},
+		TimeFormats:       map[string]string{"short": "HH:mm", "medium": "HH:mm:ss", "long": "HH:mm:ss z"},
+		DateTimeSeparator: " ",
+		Months: []string{"janvier", "février", "mars", "avril", "mai", "juin",
+			"juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		MonthsStandalone: []string{"janvier", "février", "mars", "avril", "mai", "juin",
+			"juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		ShortMonths:      []string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		Weekdays:         []string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		ShortWeekdays:    []string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+		DayPeriods:       [2]string{"AM", "PM"},
+		DecimalSeparator: ",",
+		GroupSeparator:   "\u202f",
+		PercentPattern:   "#\u202f%",
+		CurrencyPattern:  "#\u00a0¤",
+		Currency:         "EUR",
+		PluralCategories: []string{PluralOne, PluralOther},
+		pluralRule: func(i int64, v int) string {
+			if i == 0 || i == 1 {
+				return PluralOne
+			}
+
+			return PluralOther
+		},
+	},
+}
+
+// LocaleNames Returns sorted names of supported locales
+func LocaleNames() []string {
+	names := make([]string, 0, len(locales))
+
+	for name := range locales {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// ParseLocale Returns locale by name. Accepts language (ru), language tag (ru-RU) and posix locale (ru_RU.UTF-8).
+// Empty name is DefaultLocale
+func ParseLocale(name string) (*Locale, error) {
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		name = DefaultLocale
+	}
+
+	// ru_RU.UTF-8@euro -> ru-ru
+	tag := strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+
+	if i := strings.IndexAny(tag, ".@"); i >= 0 {
+		tag = tag[:i]
+	}
+
+	if locale, ok := locales[tag]; ok {
+		return locale, nil
+	}
+
+	if i := strings.Index(tag, "-"); i >= 0 {
+		if locale, ok := locales[tag[:i]]; ok {
+			return locale, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown locale %q, supported: %s", name, strings.Join(LocaleNames(), ", "))
+}
+
+// PluralCategory Returns CLDR plural category of number: one, few, many or other
+func (l *Locale) PluralCategory(n float64) string {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return PluralOther
+	}
+
+	n = math.Abs(n)
+	i := int64(n)
+	v := 0 // number of visible fraction digits
+
+	if float64(i) != n {
+		fraction := strings.SplitN(strconv.FormatFloat(n, 'f', -1, 64), ".", 2)[1]
+		v = len(fraction)
+	}
+
+	return l.pluralRule(i, v)
+}
+
+// oneOtherPluralRule English and german plural rule: 1 is one, everything else is other
+func oneOtherPluralRule(i int64, v int) string {
+	if i == 1 && v == 0 {
+		return PluralOne
+	}
+
+	return PluralOther
+}
+
+// slavicPluralRule Russian and ukrainian plural rule: 1, 21 is one; 2-4, 22-24 is few;
+// 0, 5-20, 25 is many; fractions are other
+func slavicPluralRule(i int64, v int) string {
+	if v != 0 {
+		return PluralOther
+	}
+
+	mod10, mod100 := i%10, i%100
+
+	switch {
+	case mod10 == 1 && mod100 != 11:
+		return PluralOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return PluralFew
+	}
+
+	return PluralMany
+}
@@ -0,0 +1,362 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// FormatDate Formats date with style (short, medium, long, full) or pattern (dd.MM.yyyy) in time zone.
+// Empty style is short, empty time zone is local. Invalid dates are formatted as empty string
+func (l *Locale) FormatDate(value interface{}, format string, timeZone string) (string, error) {
+	return l.formatDateValue(value, l.pattern(l.DateFormats, format), timeZone)
+}
+
+// FormatTime Formats time with style (short, medium, long) or pattern (HH:mm) in time zone
+func (l *Locale) FormatTime(value interface{}, format string, timeZone string) (string, error) {
+	return l.formatDateValue(value, l.pattern(l.TimeFormats, format), timeZone)
+}
+
+// FormatDateTime Formats date and time with style (short, medium, long, full) or pattern in time zone
+func (l *Locale) FormatDateTime(value interface{}, format string, timeZone string) (string, error) {
+	if format == "" {
+		format = "short"
+	}
+
+	datePattern, isStyle := l.DateFormats[format]
+
+	if !isStyle {
+		return l.formatDateValue(value, format, timeZone)
+	}
+
+	timePattern, ok := l.TimeFormats[format]
+
+	if !ok {
+		timePattern = l.TimeFormats["long"] // there is no full time style
+	}
+
+	return l.formatDateValue(value, datePattern+"'"+l.DateTimeSeparator+"'"+timePattern, timeZone)
+}
+
+// FormatNumber Formats number with locale decimal and group separators. Negative decimals
+// keep up to 3 fraction digits like javascript toLocaleString
+func (l *Locale) FormatNumber(value interface{}, decimals int) string {
+	f := toNumber(value)
+
+	if decimals < 0 {
+		return l.formatDecimal(f, 0, 3)
+	}
+
+	return l.formatDecimal(f, decimals, decimals)
+}
+
+// FormatCurrency Formats amount of currency (ISO 4217 code, locale currency when empty).
+// Negative decimals use currency default (2 for most currencies)
+func (l *Locale) FormatCurrency(value interface{}, currency string, decimals int) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+
+	if currency == "" {
+		currency = l.Currency
+	}
+
+	if decimals < 0 {
+		decimals = 2
+
+		if currencyDecimal, ok := currencyDecimals[currency]; ok {
+			decimals = currencyDecimal
+		}
+	}
+
+	symbol, ok := currencySymbols[currency]
+
+	if !ok {
+		symbol = currency
+	}
+
+	f := toNumber(value)
+	result := strings.Replace(l.CurrencyPattern, "#", l.formatDecimal(math.Abs(f), decimals, decimals), 1)
+	result = strings.Replace(result, "¤", symbol, 1)
+
+	if f < 0 && l.formatDecimal(-f, decimals, decimals) != l.formatDecimal(0, decimals, decimals) {
+		return "-" + result
+	}
+
+	return result
+}
+
+// FormatPercent Formats fraction as percent: 0.25 -> 25%. Negative decimals keep no fraction digits
+func (l *Locale) FormatPercent(value interface{}, decimals int) string {
+	f := toNumber(value) * 100
+
+	if decimals < 0 {
+		decimals = 0
+	}
+
+	return strings.Replace(l.PercentPattern, "#", l.formatDecimal(f, decimals, decimals), 1)
+}
+
+// Plural Returns form for plural category of count: forms by category (one, few, many, other).
+// Missing form falls back to other, then many. # in form is replaced by formatted count
+func (l *Locale) Plural(count interface{}, forms map[string]string) string {
+	n := toNumber(count)
+	form, ok := forms[l.PluralCategory(n)]
+
+	if !ok {
+		form, ok = forms[PluralOther]
+	}
+
+	if !ok {
+		form = forms[PluralMany]
+	}
+
+	return strings.ReplaceAll(form, "#", l.FormatNumber(n, -1))
+}
+
+// pattern Returns pattern of style or format itself when it is not a style name
+func (l *Locale) pattern(styles map[string]string, format string) string {
+	if format == "" {
+		format = "short"
+	}
+
+	if pattern, ok := styles[format]; ok {
+		return pattern
+	}
+
+	return format
+}
+
+// formatDecimal Formats number with at least minDecimals and at most maxDecimals fraction digits
+func (l *Locale) formatDecimal(f float64, minDecimals int, maxDecimals int) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "∞"
+	case math.IsInf(f, -1):
+		return "-∞"
+	}
+
+	sign := ""
+
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+
+	fixed := toFixed(f, maxDecimals)
+	intPart, fraction := fixed, ""
+
+	if point := strings.Index(fixed, "."); point >= 0 {
+		intPart, fraction = fixed[:point], fixed[point+1:]
+	}
+
+	for len(fraction) > minDecimals && strings.HasSuffix(fraction, "0") {
+		fraction = fraction[:len(fraction)-1]
+	}
+
+	if strings.Trim(intPart+fraction, "0") == "" {
+		sign = "" // -0.001 rounded to -0
+	}
+
+	result := sign + l.groupDigits(intPart)
+
+	if fraction != "" {
+		result += l.DecimalSeparator + fraction
+	}
+
+	return result
+}
+
+// groupDigits Inserts group separator between every 3 digits of integer part
+func (l *Locale) groupDigits(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	var builder strings.Builder
+	first := len(digits) % 3
+
+	if first > 0 {
+		builder.WriteString(digits[:first])
+	}
+
+	for i := first; i < len(digits); i += 3 {
+		if i > 0 {
+			builder.WriteString(l.GroupSeparator)
+		}
+
+		builder.WriteString(digits[i : i+3])
+	}
+
+	return builder.String()
+}
+
+// formatDateValue Converts value (time.Time, date string or milliseconds) to time zone and formats it with pattern
+func (l *Locale) formatDateValue(value interface{}, pattern string, timeZone string) (string, error) {
+	location := time.Local
+
+	if timeZone != "" {
+		var err error
+		location, err = time.LoadLocation(timeZone)
+
+		if err != nil {
+			return "", fmt.Errorf("unknown time zone %q", timeZone)
+		}
+	}
+
+	t, ok := value.(time.Time)
+
+	if !ok {
+		if t, ok = toDate(value); !ok {
+			return "", nil
+		}
+	}
+
+	return l.formatPattern(t.In(location), pattern), nil
+}
+
+// formatPattern Formats time with CLDR date pattern: y, M, L, d, E, H, h, m, s, S, a, z, Z fields
+// and quoted 'literal' text
+func (l *Locale) formatPattern(t time.Time, pattern string) string {
+	var builder strings.Builder
+
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+
+		if c == '\'' {
+			end := strings.IndexByte(pattern[i+1:], '\'')
+
+			if end < 0 {
+				builder.WriteString(pattern[i+1:])
+				break
+			}
+
+			if end == 0 {
+				builder.WriteByte('\'') // '' is quote
+			} else {
+				builder.WriteString(pattern[i+1 : i+1+end])
+			}
+
+			i += end + 2
+			continue
+		}
+
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			_, size := utf8.DecodeRuneInString(pattern[i:])
+			builder.WriteString(pattern[i : i+size])
+			i += size
+			continue
+		}
+
+		count := 1
+
+		for i+count < len(pattern) && pattern[i+count] == c {
+			count++
+		}
+
+		builder.WriteString(l.formatField(t, c, count))
+		i += count
+	}
+
+	return builder.String()
+}
+
+// formatField Formats one pattern field. Unknown fields are written as is
+func (l *Locale) formatField(t time.Time, field byte, count int) string {
+	switch field {
+	case 'y':
+		if count == 2 {
+			return fmt.Sprintf("%02d", t.Year()%100)
+		}
+
+		return fmt.Sprintf("%0*d", count, t.Year())
+	case 'M', 'L':
+		month := int(t.Month())
+
+		switch {
+		case count <= 2:
+			return fmt.Sprintf("%0*d", count, month)
+		case count == 3:
+			return l.ShortMonths[month-1]
+		case field == 'L':
+			return l.MonthsStandalone[month-1]
+		}
+
+		return l.Months[month-1]
+	case 'd':
+		return fmt.Sprintf("%0*d", count, t.Day())
+	case 'E':
+		if count == 4 {
+			return l.Weekdays[t.Weekday()]
+		}
+
+		return l.ShortWeekdays[t.Weekday()]
+	case 'H':
+		return fmt.Sprintf("%0*d", count, t.Hour())
+	case 'h':
+		hour := t.Hour() % 12
+
+		if hour == 0 {
+			hour = 12
+		}
+
+		return fmt.Sprintf("%0*d", count, hour)
+	case 'm':
+		return fmt.Sprintf("%0*d", count, t.Minute())
+	case 's':
+		return fmt.Sprintf("%0*d", count, t.Second())
+	case 'S':
+		return fmt.Sprintf("%09d", t.Nanosecond())[:minInt(count, 9)]
+	case 'a':
+		if t.Hour() < 12 {
+			return l.DayPeriods[0]
+		}
+
+		return l.DayPeriods[1]
+	case 'z':
+		zone, _ := t.Zone()
+		return zone
+	case 'Z':
+		if count >= 5 {
+			return t.Format("-07:00")
+		}
+
+		return t.Format("-0700")
+	}
+
+	return strings.Repeat(string(field), count)
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// localeInt Converts optional helper argument to int. Missing argument is -1 (default)
+func localeInt(value interface{}) int {
+	if value == nil {
+		return -1
+	}
+
+	f := toNumber(value)
+
+	if math.IsNaN(f) {
+		return -1
+	}
+
+	return int(f)
+}
+
+// localeString Converts optional helper argument to string. Missing argument is empty string
+func localeString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	return toString(value)
+}
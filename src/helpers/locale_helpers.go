@@ -0,0 +1,114 @@
+package helpers
+
+import (
+	"github.com/aymerick/raymond"
+	"text/template"
+)
+
+// localized Helpers formatting with locale set by --locale, by the name used in templates.
+// Options are passed as hash arguments: {{localeDate date format="long" tz="Europe/Moscow"}}
+var localized = map[string]func(l *Locale) interface{}{
+	"localeDate": func(l *Locale) interface{} {
+		return func(value interface{}, options *raymond.Options) string {
+			return mustFormat(l.FormatDate(value, localeString(options.HashProp("format")), localeString(options.HashProp("tz"))))
+		}
+	},
+	"localeTime": func(l *Locale) interface{} {
+		return func(value interface{}, options *raymond.Options) string {
+			return mustFormat(l.FormatTime(value, localeString(options.HashProp("format")), localeString(options.HashProp("tz"))))
+		}
+	},
+	"localeDateTime": func(l *Locale) interface{} {
+		return func(value interface{}, options *raymond.Options) string {
+			return mustFormat(l.FormatDateTime(value, localeString(options.HashProp("format")), localeString(options.HashProp("tz"))))
+		}
+	},
+	"localeNumber": func(l *Locale) interface{} {
+		return func(value interface{}, options *raymond.Options) string {
+			return l.FormatNumber(value, localeInt(options.HashProp("decimals")))
+		}
+	},
+	"localeCurrency": func(l *Locale) interface{} {
+		return func(value interface{}, options *raymond.Options) string {
+			return l.FormatCurrency(value, localeString(options.HashProp("currency")), localeInt(options.HashProp("decimals")))
+		}
+	},
+	"localePercent": func(l *Locale) interface{} {
+		return func(value interface{}, options *raymond.Options) string {
+			return l.FormatPercent(value, localeInt(options.HashProp("decimals")))
+		}
+	},
+	"plural": func(l *Locale) interface{} {
+		return func(count interface{}, options *raymond.Options) string {
+			forms := make(map[string]string)
+
+			for category, form := range options.Hash() {
+				forms[category] = toString(form)
+			}
+
+			return l.Plural(count, forms)
+		}
+	},
+}
+
+// mustFormat Returns formatted value. Errors are raised as panic, raymond returns them as evaluation errors
+func mustFormat(result string, err error) string {
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+// localizedTemplateFuncs Localized helpers for Go text/template. Options are positional and optional:
+// {{ localeDate .date "long" "Europe/Moscow" }}, {{ localeCurrency .total "EUR" 2 }}, {{ plural .count "день" "дня" "дней" }}
+// (plural forms follow locale plural categories)
+func localizedTemplateFuncs(l *Locale) template.FuncMap {
+	return template.FuncMap{
+		"localeDate": func(value interface{}, args ...string) (string, error) {
+			return l.FormatDate(value, stringArg(args, 0), stringArg(args, 1))
+		},
+		"localeTime": func(value interface{}, args ...string) (string, error) {
+			return l.FormatTime(value, stringArg(args, 0), stringArg(args, 1))
+		},
+		"localeDateTime": func(value interface{}, args ...string) (string, error) {
+			return l.FormatDateTime(value, stringArg(args, 0), stringArg(args, 1))
+		},
+		"localeNumber": func(value interface{}, decimals ...int) string {
+			return l.FormatNumber(value, intArg(decimals, 0))
+		},
+		"localeCurrency": func(value interface{}, args ...interface{}) string {
+			return l.FormatCurrency(value, localeString(argOrDefault(args, 0, nil)), localeInt(argOrDefault(args, 1, nil)))
+		},
+		"localePercent": func(value interface{}, decimals ...int) string {
+			return l.FormatPercent(value, intArg(decimals, 0))
+		},
+		"plural": func(count interface{}, forms ...string) string {
+			byCategory := make(map[string]string, len(forms))
+
+			for i, form := range forms {
+				if i < len(l.PluralCategories) {
+					byCategory[l.PluralCategories[i]] = form
+				}
+			}
+
+			return l.Plural(count, byCategory)
+		},
+	}
+}
+
+func stringArg(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+
+	return ""
+}
+
+func intArg(args []int, i int) int {
+	if i < len(args) {
+		return args[i]
+	}
+
+	return -1
+}
@@ -0,0 +1,45 @@
+package helpers
+
+import (
+	"math"
+)
+
+// Math Applies arithmetic operator (+, -, *, /, %) to values parsed with javascript parseFloat.
+// Unknown operator gives nil
+func Math(lvalue interface{}, operator interface{}, rvalue interface{}) interface{} {
+	l := parseFloat(lvalue)
+	r := parseFloat(rvalue)
+	var result float64
+
+	switch toString(operator) {
+	case "+":
+		result = l + r
+	case "-":
+		result = l - r
+	case "*":
+		result = l * r
+	case "/":
+		result = l / r
+	case "%":
+		result = math.Mod(l, r)
+	default:
+		return nil
+	}
+
+	return jsNumber(result)
+}
+
+// PercentHelper Formats fraction as percent with two decimals: 0.1234 -> 12.34%
+func PercentHelper(num interface{}) string {
+	return toFixed(toNumber(num)*100, 2) + "%"
+}
+
+// jsNumber Keeps finite numbers as float64 for further calculations. NaN and infinities are
+// returned as their javascript string representation since template engine prints them differently
+func jsNumber(f float64) interface{} {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return numberToString(f)
+	}
+
+	return f
+}
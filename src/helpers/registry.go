@@ -0,0 +1,138 @@
+// Package helpers Go implementations of the Handlebars helpers bundled in this directory as javascript files.
+//
+// Helpers follow javascript semantics of the original files (truthiness, number parsing and formatting,
+// Date parsing in local time zone). raymond evaluates paths missing in data and null values to nil, so nil values
+// behave like javascript null. Helpers with default arguments get render.Undefined for missing paths instead.
+//
+// Localized helpers (localeDate, localeNumber, plural, ...) format dates, numbers and plural forms
+// with Locale rules instead of hardcoded formats.
+package helpers
+
+import (
+	"fmt"
+	"github.com/aymerick/raymond"
+	"github.com/icewind666/html-to-excel-renderer/src/render"
+	"sort"
+	"strings"
+)
+
+// builtin Registered helpers by the name used in templates
+var builtin = map[string]interface{}{
+	"alchoHelper":          AlchoHelper,
+	"allowHelper":          AllowHelper,
+	"dashHelper":           DashHelper,
+	"dashMgtHelper":        DashMgtHelper,
+	"dashOrData":           DashOrData,
+	"faceIdNotFoundName":   FaceIdNotFoundName,
+	"formatComplains":      FormatComplains,
+	"formatDate":           FormatDate,
+	"formatDateOfBirth":    FormatDateOfBirth,
+	"formatDateTime":       FormatDateTime,
+	"formatGender":         FormatGender,
+	"formatOrganization":   FormatOrganization,
+	"formatPressure":       FormatPressure,
+	"formatResult":         FormatResult,
+	"formatStatus":         FormatStatus,
+	"formatType":           FormatType,
+	"isAfterBeforeSheet":   IsAfterBeforeSheet,
+	"ifnull":               IfNull,
+	"inspectionTimeHelper": InspectionTimeHelper,
+	"key":                  Key,
+	"lineSumRows":          LineSumRows,
+	"math":                 Math,
+	"percentHelper":        PercentHelper,
+	"pressureHelper":       PressureHelper,
+	"sleep":                Sleep,
+	"summarize":            Summarize,
+	"upper":                Upper,
+	"zeroIntHelper":        ZeroIntHelper,
+}
+
+// withDefaults Handlebars versions of helpers with javascript default arguments. raymond calls helpers with exact
+// number of arguments, so they are variadic helpers bound to number of arguments used in template
+var withDefaults = map[string]render.VariadicHelper{
+	"dashHelper": func(params []interface{}, _ *raymond.Options) interface{} {
+		return DashHelper(params...)
+	},
+	"inspectionTimeHelper": func(params []interface{}, _ *raymond.Options) interface{} {
+		return InspectionTimeHelper(params...)
+	},
+}
+
+// Names Returns sorted names of all built-in helpers, localized helpers included
+func Names() []string {
+	names := make([]string, 0, len(builtin)+len(localized))
+
+	for name := range builtin {
+		names = append(names, name)
+	}
+
+	for name := range localized {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Get Returns built-in helper by name. Localized helpers format with given locale
+func Get(locale *Locale, name string) (interface{}, bool) {
+	if newHelper, ok := localized[name]; ok {
+		return newHelper(locale), true
+	}
+
+	if helper, ok := withDefaults[name]; ok {
+		return helper, true
+	}
+
+	helper, ok := builtin[name]
+	return helper, ok
+}
+
+// All Returns all built-in helpers
+func All(locale *Locale) map[string]interface{} {
+	return Select(locale, Names()...)
+}
+
+// Select Returns built-in helpers with given names. Unknown names are skipped, use Get to check them
+func Select(locale *Locale, names ...string) map[string]interface{} {
+	result := make(map[string]interface{}, len(names))
+
+	for _, name := range names {
+		if helper, ok := Get(locale, name); ok {
+			result[name] = helper
+		}
+	}
+
+	return result
+}
+
+// ParseNames Parses comma separated list of helper names. Empty list and "all" select every built-in helper
+func ParseNames(list string) ([]string, error) {
+	list = strings.TrimSpace(list)
+
+	if list == "" || list == "all" {
+		return Names(), nil
+	}
+
+	var names []string
+
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+
+		if name == "" {
+			continue
+		}
+
+		_, isBuiltin := builtin[name]
+		_, isLocalized := localized[name]
+
+		if !isBuiltin && !isLocalized {
+			return nil, fmt.Errorf("unknown helper %q", name)
+		}
+
+		names = append(names, name)
+	}
+
+	return names, nil
+}
@@ -0,0 +1,115 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// summaryRowFormat Summary row html used by summarize helper
+const summaryRowFormat = `<tr style="height: 300px; border-style: solid;"><td colspan="4" style="text-align: left">%s</td><td></td><td></td><td></td><td>%d</td></tr>`
+
+// lineSummaryRowFormat Summary row html used by lineSumRows helper
+const lineSummaryRowFormat = `<tr style="height: 300px; border-style: solid"><td colspan="4" style="text-align: left">%s</td><td></td><td></td><td></td><td>%d</td></tr>`
+
+// allowedValue Inspection allow value for passed inspection
+const allowedValue = "Допущен"
+
+// IsAfterBeforeSheet Checks sheet is one of before/after/line inspections sheets
+func IsAfterBeforeSheet(sheetName interface{}) bool {
+	return includes(sheetName, "Предрейсовый") ||
+		includes(sheetName, "Послерейсовый") ||
+		includes(sheetName, "Линейный")
+}
+
+// LineSumRows Returns html summary rows for line inspections sheet
+func LineSumRows(obj interface{}) string {
+	inspections := inspectionsOf(obj)
+	goodLine := 0
+	badLine := 0
+
+	for _, insp := range inspections {
+		if isString(property(insp, "allow"), allowedValue) {
+			goodLine++
+		} else {
+			badLine++
+		}
+	}
+
+	return fmt.Sprintf(lineSummaryRowFormat, "Итого осмотрено: ", len(inspections)) +
+		fmt.Sprintf(lineSummaryRowFormat, "Итого прошло линейный контроль: ", goodLine) +
+		fmt.Sprintf(lineSummaryRowFormat, "Итого отстраненных от трудовых обязанностей: ", badLine)
+}
+
+// Summarize Returns html summary rows depending on sheet name (before or after inspections)
+func Summarize(obj interface{}) string {
+	sheetName := toString(property(obj, "sheetName"))
+
+	if strings.Contains(sheetName, "Предрейсовый") {
+		return beforeSumRows(obj)
+	}
+
+	if strings.Contains(sheetName, "Послерейсовый") {
+		return afterSumRows(obj)
+	}
+
+	return ""
+}
+
+func beforeSumRows(obj interface{}) string {
+	inspections := inspectionsOf(obj)
+	goodBefore := 0
+	badBefore := 0
+
+	for _, insp := range inspections {
+		inspType := property(insp, "type")
+		allowed := isString(property(insp, "allow"), allowedValue)
+
+		if isString(inspType, "Предрейсовый") || isString(inspType, "Предсменный") {
+			if allowed {
+				goodBefore++
+			} else {
+				badBefore++
+			}
+		}
+	}
+
+	return fmt.Sprintf(summaryRowFormat, "Итого осмотрено: ", len(inspections)) +
+		fmt.Sprintf(summaryRowFormat, "Итого допущено к исполнению трудовых обязанностей: ", goodBefore) +
+		fmt.Sprintf(summaryRowFormat, "Итого не допущено к исполнению трудовых обязанностей: ", badBefore)
+}
+
+func afterSumRows(obj interface{}) string {
+	inspections := inspectionsOf(obj)
+	goodAfter := 0
+	badAfter := 0
+	goodAfterShift := 0
+	badAfterShift := 0
+
+	for _, insp := range inspections {
+		allowed := isString(property(insp, "allow"), allowedValue)
+
+		if isString(property(insp, "type"), "Послерейсовый") {
+			if allowed {
+				goodAfter++
+			} else {
+				badAfter++
+			}
+		} else if allowed {
+			goodAfterShift++
+		} else {
+			badAfterShift++
+		}
+	}
+
+	return fmt.Sprintf(summaryRowFormat, "Итого осмотрено: ", len(inspections)) +
+		fmt.Sprintf(summaryRowFormat, "Итого прошло послерейсовый: ", goodAfter) +
+		fmt.Sprintf(summaryRowFormat, "Итого прошло послесменный: ", goodAfterShift) +
+		fmt.Sprintf(summaryRowFormat, "Итого не прошло послерейсовый: ", badAfter) +
+		fmt.Sprintf(summaryRowFormat, "Итого не прошло послесменный: ", badAfterShift)
+}
+
+// inspectionsOf Returns obj.inspections array
+func inspectionsOf(obj interface{}) []interface{} {
+	inspections, _ := property(obj, "inspections").([]interface{})
+	return inspections
+}
@@ -0,0 +1,262 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateFuncs Returns functions for Go text/template rendering: Sprig-like general purpose functions,
+// all built-in Handlebars helpers under their names and localized helpers formatting with locale
+func TemplateFuncs(locale *Locale) template.FuncMap {
+	funcs := template.FuncMap{
+		// defaults and conditions
+		"default":  defaultValue,
+		"empty":    empty,
+		"coalesce": coalesce,
+		"ternary":  ternary,
+
+		// strings
+		"upper":      Upper,
+		"lower":      strings.ToLower,
+		"title":      strings.Title,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix string, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix string, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old string, new string, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":   func(substr string, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix string, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix string, s string) bool { return strings.HasSuffix(s, suffix) },
+		"split":      func(sep string, s string) []string { return strings.Split(s, sep) },
+		"join":       join,
+		"repeat":     func(count int, s string) string { return strings.Repeat(s, count) },
+		"substr":     substr,
+		"trunc":      func(length int, s string) string { return substr(0, length, s) },
+		"quote":      func(value interface{}) string { return fmt.Sprintf("%q", toString(value)) },
+		"cat":        cat,
+		"toString":   toString,
+
+		// numbers
+		"add":     func(a interface{}, b interface{}) float64 { return toNumber(a) + toNumber(b) },
+		"sub":     func(a interface{}, b interface{}) float64 { return toNumber(a) - toNumber(b) },
+		"mul":     func(a interface{}, b interface{}) float64 { return toNumber(a) * toNumber(b) },
+		"div":     func(a interface{}, b interface{}) float64 { return toNumber(a) / toNumber(b) },
+		"mod":     func(a interface{}, b interface{}) float64 { return math.Mod(toNumber(a), toNumber(b)) },
+		"max":     func(a interface{}, b interface{}) float64 { return math.Max(toNumber(a), toNumber(b)) },
+		"min":     func(a interface{}, b interface{}) float64 { return math.Min(toNumber(a), toNumber(b)) },
+		"floor":   func(a interface{}) float64 { return math.Floor(toNumber(a)) },
+		"ceil":    func(a interface{}) float64 { return math.Ceil(toNumber(a)) },
+		"round":   round,
+		"toInt":   func(a interface{}) int { return int(toNumber(a)) },
+		"toFloat": toNumber,
+		"toFixed": func(digits int, a interface{}) string { return toFixed(toNumber(a), digits) },
+
+		// dates
+		"now":  time.Now,
+		"date": formatGoDate,
+
+		// lists and dictionaries
+		"list":   func(items ...interface{}) []interface{} { return items },
+		"dict":   dict,
+		"keys":   keys,
+		"hasKey": func(obj map[string]interface{}, key string) bool { _, ok := obj[key]; return ok },
+		"get":    property,
+		"first":  first,
+		"last":   last,
+		"until":  until,
+		"toJson": toJson,
+	}
+
+	for name, helper := range builtin {
+		if _, exists := funcs[name]; !exists {
+			funcs[name] = helper
+		}
+	}
+
+	for name, helper := range localizedTemplateFuncs(locale) {
+		funcs[name] = helper
+	}
+
+	return funcs
+}
+
+// defaultValue Returns value or default when value is empty: {{ .name | default "-" }}
+func defaultValue(def interface{}, value ...interface{}) interface{} {
+	if len(value) == 0 || empty(value[0]) {
+		return def
+	}
+
+	return value[0]
+}
+
+// empty Checks value is nil, false, zero, empty string, list or dictionary
+func empty(value interface{}) bool {
+	switch v := value.(type) {
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	case []string:
+		return len(v) == 0
+	}
+
+	return !truthy(value)
+}
+
+// coalesce Returns first non-empty value
+func coalesce(values ...interface{}) interface{} {
+	for _, value := range values {
+		if !empty(value) {
+			return value
+		}
+	}
+
+	return nil
+}
+
+// ternary Returns first value if condition is true: {{ .allowed | ternary "Да" "Нет" }}
+func ternary(ifTrue interface{}, ifFalse interface{}, condition interface{}) interface{} {
+	if truthy(condition) {
+		return ifTrue
+	}
+
+	return ifFalse
+}
+
+// join Joins list items with separator
+func join(sep string, list interface{}) string {
+	switch v := list.(type) {
+	case []string:
+		return strings.Join(v, sep)
+	case []interface{}:
+		parts := make([]string, len(v))
+
+		for i, item := range v {
+			parts[i] = toString(item)
+		}
+
+		return strings.Join(parts, sep)
+	}
+
+	return toString(list)
+}
+
+// substr Returns runes from start to end of s
+func substr(start int, end int, s string) string {
+	runes := []rune(s)
+
+	if start < 0 {
+		start = 0
+	}
+
+	if end < 0 || end > len(runes) {
+		end = len(runes)
+	}
+
+	if start > end {
+		return ""
+	}
+
+	return string(runes[start:end])
+}
+
+// cat Joins values with spaces skipping nils
+func cat(values ...interface{}) string {
+	parts := make([]string, 0, len(values))
+
+	for _, value := range values {
+		if value != nil {
+			parts = append(parts, toString(value))
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// round Rounds number to given precision: {{ round 2 .value }}
+func round(precision int, value interface{}) float64 {
+	pow := math.Pow(10, float64(precision))
+	return math.Round(toNumber(value)*pow) / pow
+}
+
+// formatGoDate Formats date value (string, milliseconds or time.Time) with Go layout in local time zone
+func formatGoDate(layout string, value interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		return t.In(time.Local).Format(layout)
+	}
+
+	if t, ok := toDate(value); ok {
+		return t.Format(layout)
+	}
+
+	return ""
+}
+
+// dict Makes dictionary from key value pairs
+func dict(pairs ...interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(pairs)/2)
+
+	for i := 0; i+1 < len(pairs); i += 2 {
+		result[toString(pairs[i])] = pairs[i+1]
+	}
+
+	return result
+}
+
+// keys Returns sorted keys of dictionary
+func keys(obj map[string]interface{}) []string {
+	result := make([]string, 0, len(obj))
+
+	for key := range obj {
+		result = append(result, key)
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+func first(list []interface{}) interface{} {
+	if len(list) == 0 {
+		return nil
+	}
+
+	return list[0]
+}
+
+func last(list []interface{}) interface{} {
+	if len(list) == 0 {
+		return nil
+	}
+
+	return list[len(list)-1]
+}
+
+// until Returns list of integers from 0 to count-1
+func until(count int) []int {
+	if count < 0 {
+		count = 0
+	}
+
+	result := make([]int, count)
+
+	for i := range result {
+		result[i] = i
+	}
+
+	return result
+}
+
+// toJson Serializes value to json
+func toJson(value interface{}) string {
+	result, err := json.Marshal(value)
+
+	if err != nil {
+		return ""
+	}
+
+	return string(result)
+}
@@ -0,0 +1,129 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AlchoHelper Formats alcohol test value for given step
+func AlchoHelper(step interface{}, value interface{}) interface{} {
+	if isNumber(step, 0) && isString(value, "0.0") {
+		return "-"
+	}
+
+	if isNumber(step, 1) && !isString(value, "0.0") {
+		return "Обнаружен"
+	}
+
+	return value
+}
+
+// AllowHelper Returns Прошел for allowed inspection
+func AllowHelper(allow interface{}) string {
+	if isString(allow, "Допущен") {
+		return "Прошел"
+	}
+
+	return "Не прошел"
+}
+
+// DashHelper Replaces zero value with dash. Value defaults to dash when omitted
+func DashHelper(values ...interface{}) interface{} {
+	value := argOrDefault(values, 0, "-")
+
+	if isString(value, "0") || isNumber(value, 0) {
+		return "-"
+	}
+
+	return value
+}
+
+// DashMgtHelper Replaces empty measurement (0/0 or 0.0) with dash
+func DashMgtHelper(value interface{}) interface{} {
+	if isString(value, "0/0") || isString(value, "0.0") {
+		return "-"
+	}
+
+	return value
+}
+
+// DashOrData Replaces null with dash
+func DashOrData(data interface{}) interface{} {
+	if data == nil {
+		return "-"
+	}
+
+	return data
+}
+
+// FaceIdNotFoundName Returns full name when face id was recognized
+func FaceIdNotFoundName(name interface{}, surname interface{}, patronymic interface{}) string {
+	if truthy(name) {
+		return fmt.Sprintf("%s %s %s", toString(surname), toString(name), toString(patronymic))
+	}
+
+	return "нет соответствия"
+}
+
+// IfNull Returns obj or default value when obj is falsy
+func IfNull(obj interface{}, ifNull interface{}) interface{} {
+	if truthy(obj) {
+		return obj
+	}
+
+	return ifNull
+}
+
+// InspectionTimeHelper Formats inspection time as min:sec. Omitted minutes and seconds default to 00
+func InspectionTimeHelper(values ...interface{}) string {
+	return fmt.Sprintf("%s:%s", toString(argOrDefault(values, 0, "00")), toString(argOrDefault(values, 1, "00")))
+}
+
+// Key Returns obj[key]
+func Key(obj interface{}, key interface{}) interface{} {
+	return property(obj, key)
+}
+
+// PressureHelper Returns pressure when both pressure values are present, dash otherwise
+func PressureHelper(pressure interface{}, upper interface{}) interface{} {
+	if truthy(pressure) && truthy(upper) && !isNumber(upper, 0) {
+		return pressure
+	}
+
+	return "-"
+}
+
+// Sleep Formats whether driver slept more than 8 hours. Dash for null
+func Sleep(sleep interface{}) string {
+	if sleep == nil {
+		return "-"
+	}
+
+	if truthy(sleep) {
+		return "более 8 часов"
+	}
+
+	return "менее 8 часов"
+}
+
+// Upper Converts strings to upper case. Other values are returned as is
+func Upper(str interface{}) interface{} {
+	if s, ok := str.(string); ok {
+		return strings.ToUpper(s)
+	}
+
+	return str
+}
+
+// ZeroIntHelper Pads number less than 10 with leading zero. Falsy values become 00
+func ZeroIntHelper(value interface{}) interface{} {
+	if !truthy(value) {
+		return "00"
+	}
+
+	if toNumber(value) < 10 {
+		return "0" + toString(value)
+	}
+
+	return value
+}
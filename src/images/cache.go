@@ -0,0 +1,82 @@
+package images
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	log "github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CachingFetcher Fetcher keeping fetched images in directory, so images are fetched once for all runs
+// until cache entries are older than TTL. Failed fetches are not cached
+type CachingFetcher struct {
+	Fetcher Fetcher
+	Dir     string
+	TTL     time.Duration // max age of cache entries, 0 is unlimited
+}
+
+// NewCachingFetcher Returns fetcher caching images of given fetcher in directory
+func NewCachingFetcher(fetcher Fetcher, dir string, ttl time.Duration) *CachingFetcher {
+	return &CachingFetcher{Fetcher: fetcher, Dir: dir, TTL: ttl}
+}
+
+// Fetch Returns cached image of url or fetches and caches it. Cache errors are logged, image is fetched then
+func (f *CachingFetcher) Fetch(url string) ([]byte, error) {
+	path := f.path(url)
+
+	if info, err := os.Stat(path); err == nil && (f.TTL <= 0 || time.Since(info.ModTime()) < f.TTL) {
+		if data, err := ioutil.ReadFile(path); err == nil {
+			return data, nil
+		}
+	}
+
+	data, err := f.Fetcher.Fetch(url)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.store(path, data); err != nil {
+		log.WithError(err).Warnf("Cant cache image %s", url)
+	}
+
+	return data, nil
+}
+
+// path Returns cache file of url
+func (f *CachingFetcher) path(url string) string {
+	hash := sha256.Sum256([]byte(url))
+	return filepath.Join(f.Dir, hex.EncodeToString(hash[:]))
+}
+
+// store Writes cache file through temporary file, so concurrent runs don't read partial files
+func (f *CachingFetcher) store(path string, data []byte) error {
+	if err := os.MkdirAll(f.Dir, 0755); err != nil {
+		return err
+	}
+
+	temp, err := ioutil.TempFile(f.Dir, "fetch-*")
+
+	if err != nil {
+		return err
+	}
+
+	_, err = temp.Write(data)
+
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(temp.Name(), path)
+	}
+
+	if err != nil {
+		os.Remove(temp.Name())
+	}
+
+	return err
+}
@@ -0,0 +1,114 @@
+package images
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+// countingFetcher Fetcher counting fetches, url "fail" fails
+type countingFetcher struct {
+	fetches int
+}
+
+func (f *countingFetcher) Fetch(url string) ([]byte, error) {
+	f.fetches++
+
+	if url == "fail" {
+		return nil, errors.New("fetch failed")
+	}
+
+	return []byte(url), nil
+}
+
+// newCachingFetcher Returns caching fetcher of counting fetcher in temporary directory
+func newCachingFetcher(t *testing.T, ttl time.Duration) (*CachingFetcher, *countingFetcher) {
+	dir, err := ioutil.TempDir("", "images-cache")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	fetcher := &countingFetcher{}
+	return NewCachingFetcher(fetcher, dir, ttl), fetcher
+}
+
+// fetch Fetches url expecting its content
+func fetch(t *testing.T, fetcher Fetcher, url string) {
+	t.Helper()
+
+	if data, err := fetcher.Fetch(url); err != nil || string(data) != url {
+		t.Fatalf("fetch %s = %q, %v", url, data, err)
+	}
+}
+
+func TestCachingFetcherHitAndMiss(t *testing.T) {
+	cache, fetcher := newCachingFetcher(t, time.Hour)
+
+	fetch(t, cache, "a")
+	fetch(t, cache, "a")
+	fetch(t, cache, "b")
+
+	if fetcher.fetches != 2 {
+		t.Errorf("%d fetches, want 2: one per url", fetcher.fetches)
+	}
+
+	// cache is kept in directory for next runs
+	next := NewCachingFetcher(fetcher, cache.Dir, time.Hour)
+	fetch(t, next, "a")
+
+	if fetcher.fetches != 2 {
+		t.Errorf("%d fetches, want cached image of previous run", fetcher.fetches)
+	}
+}
+
+func TestCachingFetcherDoesntCacheErrors(t *testing.T) {
+	cache, fetcher := newCachingFetcher(t, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Fetch("fail"); err == nil {
+			t.Fatal("fetch succeeded, want error")
+		}
+	}
+
+	if fetcher.fetches != 2 {
+		t.Errorf("%d fetches, want failed fetch repeated", fetcher.fetches)
+	}
+}
+
+func TestCachingFetcherExpires(t *testing.T) {
+	cache, fetcher := newCachingFetcher(t, time.Hour)
+	fetch(t, cache, "a")
+
+	old := time.Now().Add(-2 * time.Hour)
+
+	if err := os.Chtimes(cache.path("a"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	fetch(t, cache, "a")
+
+	if fetcher.fetches != 2 {
+		t.Errorf("%d fetches, want expired image fetched again", fetcher.fetches)
+	}
+
+	fetch(t, cache, "a")
+
+	if fetcher.fetches != 2 {
+		t.Errorf("%d fetches, want image fetched again to be cached", fetcher.fetches)
+	}
+
+	if err := os.Chtimes(cache.path("a"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	unlimited := NewCachingFetcher(fetcher, cache.Dir, 0)
+	fetch(t, unlimited, "a")
+
+	if fetcher.fetches != 2 {
+		t.Errorf("%d fetches, want cache entries kept forever without TTL", fetcher.fetches)
+	}
+}
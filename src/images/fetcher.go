@@ -0,0 +1,75 @@
+package images
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// Fetcher Loads content of image url. Resolver uses fetchers by url scheme (http, https...)
+type Fetcher interface {
+	Fetch(url string) ([]byte, error)
+}
+
+// FetcherFunc Function used as fetcher
+type FetcherFunc func(url string) ([]byte, error)
+
+// Fetch Calls function
+func (f FetcherFunc) Fetch(url string) ([]byte, error) {
+	return f(url)
+}
+
+// HTTPFetcher Loads images by http GET requests
+type HTTPFetcher struct {
+	Client  *http.Client
+	MaxSize int64 // max image size in bytes, 0 is unlimited
+}
+
+// NewHTTPFetcher Returns fetcher with request timeout and max image size
+func NewHTTPFetcher(timeout time.Duration, maxSize int64) *HTTPFetcher {
+	return &HTTPFetcher{Client: &http.Client{Timeout: timeout}, MaxSize: maxSize}
+}
+
+// Fetch Returns body of successful response. Responses larger than max size are errors
+func (f *HTTPFetcher) Fetch(url string) ([]byte, error) {
+	response, err := f.Client.Get(url)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http status %s", response.Status)
+	}
+
+	if f.MaxSize > 0 && response.ContentLength > f.MaxSize {
+		return nil, sizeError(f.MaxSize)
+	}
+
+	var body io.Reader = response.Body
+
+	if f.MaxSize > 0 {
+		body = io.LimitReader(body, f.MaxSize+1) // content length may be unknown or wrong
+	}
+
+	data, err := ioutil.ReadAll(body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if f.MaxSize > 0 && int64(len(data)) > f.MaxSize {
+		return nil, sizeError(f.MaxSize)
+	}
+
+	return data, nil
+}
+
+// sizeError Returns error of image larger than max size
+func sizeError(maxSize int64) error {
+	return fmt.Errorf("image exceeds max size of %d bytes", maxSize)
+}
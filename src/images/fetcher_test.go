@@ -0,0 +1,96 @@
+package images
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPFetcherLimitsSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := strings.Repeat("x", 10)
+
+		if r.URL.Query().Get("chunked") != "" {
+			w.(http.Flusher).Flush() // no content length, body is cut by limit reader
+		}
+
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		url     string
+		maxSize int64
+		wantErr bool
+	}{
+		{server.URL, 10, false},
+		{server.URL, 9, true},
+		{server.URL + "?chunked=1", 10, false},
+		{server.URL + "?chunked=1", 9, true},
+		{server.URL + "?chunked=1", 0, false},
+	}
+
+	for _, test := range tests {
+		data, err := NewHTTPFetcher(time.Second, test.maxSize).Fetch(test.url)
+
+		if test.wantErr && (err == nil || err.Error() != sizeError(test.maxSize).Error()) {
+			t.Errorf("fetch %s with max size %d: error %v, want size error", test.url, test.maxSize, err)
+		}
+
+		if !test.wantErr && (err != nil || len(data) != 10) {
+			t.Errorf("fetch %s with max size %d: %d bytes, error %v", test.url, test.maxSize, len(data), err)
+		}
+	}
+}
+
+func TestHTTPFetcherTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	start := time.Now()
+
+	if _, err := NewHTTPFetcher(50*time.Millisecond, 0).Fetch(server.URL); err == nil {
+		t.Error("fetch of slow server succeeded, want timeout error")
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("fetch of slow server took %v", elapsed)
+	}
+}
+
+func TestHTTPFetcherRejectsFailedResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/error":
+			http.Error(w, "error", http.StatusInternalServerError)
+		case "/moved":
+			http.Redirect(w, r, "/image", http.StatusFound)
+		default:
+			w.Write([]byte("image"))
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewHTTPFetcher(time.Second, 0)
+
+	for _, path := range []string{"/missing", "/error"} {
+		if data, err := fetcher.Fetch(server.URL + path); err == nil {
+			t.Errorf("fetch %s = %q, want status error", path, data)
+		}
+	}
+
+	if data, err := fetcher.Fetch(server.URL + "/moved"); err != nil || string(data) != "image" {
+		t.Errorf("fetch of redirect = %q, %v, want image of redirect target", data, err)
+	}
+}
@@ -0,0 +1,238 @@
+// Package images Loads images of html documents: data uris, local files relative to base directory
+// and remote urls fetched by pluggable fetchers
+package images
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dataUriScheme Scheme of images embedded to html: data:image/png;base64,...
+const dataUriScheme = "data:"
+
+// Resolver Loads images by src of <img>. Relative sources are resolved against base url of document
+// (<base href>) or base directory. Loaded images (and errors) are kept, so every image is loaded once
+type Resolver struct {
+	BaseDir  string             // directory of relative file paths, working directory when empty
+	Base     *url.URL           // base url of document, relative sources are resolved against it when set
+	Fetchers map[string]Fetcher // fetchers of remote images by url scheme
+	MaxSize  int64              // max size of local and data uri images in bytes, 0 is unlimited
+
+	loaded map[string]loadResult
+}
+
+// loadResult Image loaded or error of loading
+type loadResult struct {
+	data []byte
+	err  error
+}
+
+// NewResolver Returns resolver of files relative to base directory without fetchers
+func NewResolver(baseDir string, maxSize int64) *Resolver {
+	return &Resolver{BaseDir: baseDir, MaxSize: maxSize, Fetchers: make(map[string]Fetcher)}
+}
+
+// SetBase Sets base of document from <base href>: url (https://cdn.example.com/img/, file:///srv/templates/)
+// or directory path. Relative directory is relative to base directory
+func (r *Resolver) SetBase(href string) error {
+	base, err := url.Parse(href)
+
+	if err != nil {
+		return fmt.Errorf("invalid base %q: %w", href, err)
+	}
+
+	if isUrlScheme(base.Scheme) {
+		r.Base = base
+		return nil
+	}
+
+	r.BaseDir = r.filePath(href)
+	return nil
+}
+
+// Load Returns image content by source: data uri, url or file path
+func (r *Resolver) Load(src string) ([]byte, error) {
+	src = strings.TrimSpace(src)
+
+	if src == "" {
+		return nil, errors.New("no image source")
+	}
+
+	if strings.HasPrefix(strings.ToLower(src), dataUriScheme) {
+		return r.decodeDataUri(src)
+	}
+
+	location := r.location(src)
+
+	if result, ok := r.loaded[location]; ok {
+		return result.data, result.err
+	}
+
+	data, err := r.load(location)
+
+	if r.loaded == nil {
+		r.loaded = make(map[string]loadResult)
+	}
+
+	r.loaded[location] = loadResult{data: data, err: err}
+	return data, err
+}
+
+// location Returns absolute url or file path of source
+func (r *Resolver) location(src string) string {
+	reference, err := url.Parse(src)
+
+	if err == nil && isUrlScheme(reference.Scheme) {
+		return reference.String()
+	}
+
+	if err == nil && r.Base != nil {
+		return r.Base.ResolveReference(reference).String()
+	}
+
+	return r.filePath(src)
+}
+
+// load Loads image of absolute url or file path
+func (r *Resolver) load(location string) ([]byte, error) {
+	locationUrl, err := url.Parse(location)
+
+	if err != nil || !isUrlScheme(locationUrl.Scheme) {
+		return r.readFile(location)
+	}
+
+	if locationUrl.Scheme == "file" {
+		return r.readFile(filepath.FromSlash(locationUrl.Path))
+	}
+
+	fetcher, ok := r.Fetchers[locationUrl.Scheme]
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported image url scheme %q", locationUrl.Scheme)
+	}
+
+	return fetcher.Fetch(location)
+}
+
+// readFile Returns content of image file not larger than max size
+func (r *Resolver) readFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if r.MaxSize > 0 && info.Size() > r.MaxSize {
+		return nil, sizeError(r.MaxSize)
+	}
+
+	return ioutil.ReadFile(path)
+}
+
+// decodeDataUri Returns data of data uri not larger than max size. Size of base64 data is checked
+// before decoding, so oversized data uris are not decoded into memory
+func (r *Resolver) decodeDataUri(uri string) ([]byte, error) {
+	payload, isBase64, err := splitDataUri(uri)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if isBase64 && r.MaxSize > 0 &&
+		int64(base64.RawStdEncoding.DecodedLen(len(strings.TrimRight(payload, "=")))) > r.MaxSize {
+		return nil, sizeError(r.MaxSize)
+	}
+
+	data, err := decodeDataPayload(payload, isBase64)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if r.MaxSize > 0 && int64(len(data)) > r.MaxSize {
+		return nil, sizeError(r.MaxSize)
+	}
+
+	return data, nil
+}
+
+// filePath Returns path relative to base directory, absolute paths are returned as is
+func (r *Resolver) filePath(path string) string {
+	if filepath.IsAbs(path) || r.BaseDir == "" {
+		return filepath.Clean(path)
+	}
+
+	return filepath.Join(r.BaseDir, path)
+}
+
+// isUrlScheme Checks scheme is url scheme. One letter schemes are windows drives (C:\images\logo.png)
+func isUrlScheme(scheme string) bool {
+	return len(scheme) > 1
+}
+
+// DecodeDataUri Returns data of data uri: data:[<media type>][;base64],<data>
+func DecodeDataUri(uri string) ([]byte, error) {
+	payload, isBase64, err := splitDataUri(uri)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeDataPayload(payload, isBase64)
+}
+
+// splitDataUri Returns data of data uri and whether it is base64 encoded. Whitespace is removed from base64 data
+func splitDataUri(uri string) (string, bool, error) {
+	separator := strings.Index(uri, ",")
+
+	if separator < 0 {
+		return "", false, errors.New("invalid data uri: no data")
+	}
+
+	header, data := uri[len(dataUriScheme):separator], uri[separator+1:]
+
+	if !strings.HasSuffix(strings.ToLower(header), ";base64") {
+		return data, false, nil
+	}
+
+	data = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' {
+			return -1 // base64 of html attributes may be split to lines
+		}
+
+		return r
+	}, data)
+
+	return data, true, nil
+}
+
+// decodeDataPayload Decodes base64 or url encoded data of data uri
+func decodeDataPayload(data string, isBase64 bool) ([]byte, error) {
+	if !isBase64 {
+		decoded, err := url.PathUnescape(data)
+		return []byte(decoded), err
+	}
+
+	if decoded, err := base64.StdEncoding.DecodeString(data); err == nil {
+		return decoded, nil
+	}
+
+	return base64.RawStdEncoding.DecodeString(strings.TrimRight(data, "="))
+}
+
+// ShortSource Returns image source for logs: data uris are cut to media type
+func ShortSource(src string) string {
+	if strings.HasPrefix(strings.ToLower(src), dataUriScheme) {
+		if separator := strings.Index(src, ","); separator >= 0 {
+			return src[:separator] + ",..."
+		}
+	}
+
+	return fmt.Sprintf("%q", src)
+}
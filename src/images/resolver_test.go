@@ -0,0 +1,36 @@
+package images
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolverLimitsDataUriSize(t *testing.T) {
+	tests := []struct {
+		src     string
+		maxSize int64
+		wantErr bool
+	}{
+		{"data:image/png;base64,AAECAwQ=", 5, false}, // 5 bytes
+		{"data:image/png;base64,AAECAwQ=", 4, true},
+		{"data:image/png;base64,AAECAwQ=", 0, false},
+		{"data:text/plain,abcde", 4, true},
+		{"data:text/plain,%61%62%63%64", 4, false},      // size of decoded data
+		{"data:image/png;base64,AAEC\n  AwQ", 5, false}, // whitespace and missing padding are not counted
+		{"data:image/png;base64,AAECAwQFBgc=", 8, false},
+		{"data:image/png;base64,AAECAwQFBgc=", 7, true},
+		{"data:image/png;base64," + strings.Repeat("!", 1000), 100, true}, // checked before decoding
+	}
+
+	for _, test := range tests {
+		_, err := NewResolver("", test.maxSize).Load(test.src)
+
+		if test.wantErr && (err == nil || err.Error() != sizeError(test.maxSize).Error()) {
+			t.Errorf("load %s with max size %d: error %v, want size error", test.src, test.maxSize, err)
+		}
+
+		if !test.wantErr && err != nil {
+			t.Errorf("load %s with max size %d: %v", test.src, test.maxSize, err)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package jshelpers
+
+// moduleWrapper Wraps helper file into CommonJS-like module function. Opening part is kept on the
+// first line so line numbers in javascript errors match the helper file
+const moduleWrapperStart = "(function (module, exports, require) {"
+const moduleWrapperEnd = "\n})"
+
+// prelude Handlebars object exposed to helper files. Native functions are set by Runtime before evaluation
+const prelude = `
+var Handlebars = (function () {
+    function SafeString(string) {
+        this.string = string;
+    }
+
+    SafeString.prototype.toString = SafeString.prototype.toHTML = function () {
+        return '' + this.string;
+    };
+
+    return {
+        SafeString: SafeString,
+        escapeExpression: __escapeExpression,
+        Utils: {escapeExpression: __escapeExpression},
+        registerHelper: function (name, fn) {
+            if (typeof name === 'object') {
+                for (var key in name) {
+                    __registerHelper(key, name[key]);
+                }
+                return;
+            }
+            __registerHelper(name, fn);
+        }
+    };
+})();
+`
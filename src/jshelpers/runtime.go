@@ -0,0 +1,330 @@
+// Package jshelpers Runs javascript Handlebars helpers (X.register = function (Handlebars) {...}) in embedded
+// javascript runtime, so helper files written for hbs-cli work with in-process rendering
+package jshelpers
+
+import (
+	"fmt"
+	"github.com/aymerick/raymond"
+	"github.com/dop251/goja"
+	"github.com/icewind666/html-to-excel-renderer/src/render"
+	log "github.com/sirupsen/logrus"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout Max execution time of one helper call
+const DefaultTimeout = 5 * time.Second
+
+// Runtime Javascript runtime with loaded helpers. Not safe for concurrent use
+type Runtime struct {
+	Timeout time.Duration
+
+	vm         *goja.Runtime
+	handlebars goja.Value
+	helpers    map[string]goja.Callable
+	files      map[string]string // helper name -> file it was registered from
+	loading    string            // file being loaded right now
+}
+
+// HelperError Error thrown by javascript helper or helper file
+type HelperError struct {
+	Helper string
+	File   string
+	Err    error
+}
+
+func (e *HelperError) Error() string {
+	if e.Helper == "" {
+		return fmt.Sprintf("javascript helpers file %s: %s", e.File, e.Err)
+	}
+
+	return fmt.Sprintf("javascript helper %s (%s): %s", e.Helper, e.File, e.Err)
+}
+
+// New Creates runtime with Handlebars object and console available to helper files
+func New() *Runtime {
+	r := &Runtime{
+		Timeout: DefaultTimeout,
+		vm:      goja.New(),
+		helpers: make(map[string]goja.Callable),
+		files:   make(map[string]string),
+	}
+
+	r.mustSet("__registerHelper", r.registerHelper)
+	r.mustSet("__escapeExpression", func(value goja.Value) string {
+		return raymond.Escape(raymond.Str(r.export(value)))
+	})
+	r.mustSet("console", map[string]interface{}{
+		"log":   func(args ...interface{}) { log.Info(args...) },
+		"info":  func(args ...interface{}) { log.Info(args...) },
+		"warn":  func(args ...interface{}) { log.Warn(args...) },
+		"error": func(args ...interface{}) { log.Error(args...) },
+	})
+
+	if _, err := r.vm.RunString(prelude); err != nil {
+		panic(err) // prelude is a constant, fails only on programming error
+	}
+
+	r.handlebars = r.vm.Get("Handlebars")
+	return r
+}
+
+// LoadDir Loads all *.js files from directory in alphabetical order
+func (r *Runtime) LoadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.js"))
+
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(files)
+
+	for _, file := range files {
+		if err := r.LoadFile(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadFile Evaluates helper file as module and registers its helpers. Module should export
+// object with register(Handlebars) function or object with helper functions
+func (r *Runtime) LoadFile(filename string) error {
+	source, err := ioutil.ReadFile(filename)
+
+	if err != nil {
+		return &HelperError{File: filename, Err: err}
+	}
+
+	program, err := goja.Compile(filename, moduleWrapperStart+string(source)+moduleWrapperEnd, false)
+
+	if err != nil {
+		return &HelperError{File: filename, Err: err}
+	}
+
+	r.loading = filename
+	defer func() { r.loading = "" }()
+
+	err = r.withTimeout(filename, func() error {
+		wrapper, err := r.vm.RunProgram(program)
+
+		if err != nil {
+			return err
+		}
+
+		moduleFunc, _ := goja.AssertFunction(wrapper)
+		module := r.vm.NewObject()
+		exports := r.vm.NewObject()
+		_ = module.Set("exports", exports)
+
+		if _, err := moduleFunc(goja.Undefined(), module, exports, r.vm.ToValue(r.require)); err != nil {
+			return err
+		}
+
+		return r.registerExports(module.Get("exports"))
+	})
+
+	if err != nil {
+		return &HelperError{File: filename, Err: err}
+	}
+
+	return nil
+}
+
+// registerExports Registers helpers exported by module
+func (r *Runtime) registerExports(exports goja.Value) error {
+	obj, ok := exports.(*goja.Object)
+
+	if !ok {
+		return fmt.Errorf("module.exports is not an object")
+	}
+
+	if register, ok := goja.AssertFunction(obj.Get("register")); ok {
+		_, err := register(obj, r.handlebars)
+		return err
+	}
+
+	for _, key := range obj.Keys() {
+		if fn, ok := goja.AssertFunction(obj.Get(key)); ok {
+			r.helpers[key] = fn
+			r.files[key] = r.loading
+		}
+	}
+
+	return nil
+}
+
+// registerHelper Handlebars.registerHelper implementation
+func (r *Runtime) registerHelper(name string, fn goja.Value) {
+	callable, ok := goja.AssertFunction(fn)
+
+	if !ok {
+		panic(r.vm.NewTypeError("helper %s is not a function", name))
+	}
+
+	if previous, exists := r.files[name]; exists {
+		log.Warnf("Javascript helper %s from %s is overridden by %s", name, previous, r.loading)
+	}
+
+	r.helpers[name] = callable
+	r.files[name] = r.loading
+}
+
+// require Supports only require('handlebars')
+func (r *Runtime) require(name string) goja.Value {
+	if name == "handlebars" {
+		return r.handlebars
+	}
+
+	panic(r.vm.NewTypeError("require('%s') is not supported in helper files", name))
+}
+
+// Names Returns sorted names of loaded helpers
+func (r *Runtime) Names() []string {
+	names := make([]string, 0, len(r.helpers))
+
+	for name := range r.helpers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Helpers Returns loaded helpers for template rendering
+func (r *Runtime) Helpers() map[string]interface{} {
+	result := make(map[string]interface{}, len(r.helpers))
+
+	for name := range r.helpers {
+		name := name
+		result[name] = render.VariadicHelper(func(params []interface{}, options *raymond.Options) interface{} {
+			return r.call(name, params, options)
+		})
+	}
+
+	return result
+}
+
+// call Calls helper like Handlebars.js does: template arguments, then options object, with context as this.
+// Panics with HelperError, raymond returns it as rendering error
+func (r *Runtime) call(name string, params []interface{}, options *raymond.Options) interface{} {
+	args := make([]goja.Value, 0, len(params)+1)
+
+	for _, param := range params {
+		if param == render.Undefined {
+			args = append(args, goja.Undefined()) // path missing in data
+			continue
+		}
+
+		args = append(args, r.vm.ToValue(param))
+	}
+
+	args = append(args, r.options(name, options))
+	var result goja.Value
+
+	err := r.withTimeout(name, func() error {
+		var err error
+		result, err = r.helpers[name](r.vm.ToValue(helperContext(options)), args...)
+		return err
+	})
+
+	if err != nil {
+		panic(&HelperError{Helper: name, File: r.files[name], Err: err})
+	}
+
+	return r.export(result)
+}
+
+// helperContext Returns context of helper call, nil when template is rendered without data.
+// raymond panics on Ctx() of missing context instead of returning nil
+func helperContext(options *raymond.Options) (ctx interface{}) {
+	defer func() {
+		if recover() != nil {
+			ctx = nil
+		}
+	}()
+
+	return options.Ctx()
+}
+
+// options Creates Handlebars.js options argument
+func (r *Runtime) options(name string, options *raymond.Options) *goja.Object {
+	hash := options.Hash()
+
+	if hash == nil {
+		hash = make(map[string]interface{}) // Handlebars.js passes empty hash to calls without one
+	}
+
+	obj := r.vm.NewObject()
+	_ = obj.Set("name", name)
+	_ = obj.Set("hash", hash)
+	_ = obj.Set("data", map[string]interface{}{
+		"root":  options.Data("root"),
+		"index": options.Data("index"),
+		"key":   options.Data("key"),
+		"first": options.Data("first"),
+		"last":  options.Data("last"),
+	})
+	_ = obj.Set("fn", func(call goja.FunctionCall) goja.Value {
+		if ctx := call.Argument(0); !goja.IsUndefined(ctx) {
+			return r.vm.ToValue(options.FnWith(ctx.Export()))
+		}
+		return r.vm.ToValue(options.Fn())
+	})
+	_ = obj.Set("inverse", func(call goja.FunctionCall) goja.Value {
+		return r.vm.ToValue(options.Inverse())
+	})
+
+	return obj
+}
+
+// withTimeout Runs javascript code interrupting it after Timeout
+func (r *Runtime) withTimeout(name string, run func() error) error {
+	var mutex sync.Mutex
+	finished := false
+
+	timer := time.AfterFunc(r.Timeout, func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if !finished {
+			r.vm.Interrupt(fmt.Sprintf("%s timed out after %s", name, r.Timeout))
+		}
+	})
+
+	err := run()
+
+	mutex.Lock()
+	finished = true
+	mutex.Unlock()
+	timer.Stop()
+	r.vm.ClearInterrupt()
+
+	return err
+}
+
+// export Converts javascript value to template value. Objects with toHTML (Handlebars.SafeString) are not escaped
+func (r *Runtime) export(value goja.Value) interface{} {
+	if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+		return nil
+	}
+
+	if obj, ok := value.(*goja.Object); ok {
+		if _, isSafe := goja.AssertFunction(obj.Get("toHTML")); isSafe {
+			return raymond.SafeString(obj.String())
+		}
+	}
+
+	return value.Export()
+}
+
+// mustSet Sets global value in runtime
+func (r *Runtime) mustSet(name string, value interface{}) {
+	if err := r.vm.Set(name, value); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,207 @@
+package jshelpers
+
+import (
+	"github.com/aymerick/raymond"
+	"github.com/icewind666/html-to-excel-renderer/src/render"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// loadRuntime Returns runtime with helper files (source by file name) loaded from temporary directory
+func loadRuntime(t *testing.T, files map[string]string) (*Runtime, string) {
+	dir, err := ioutil.TempDir("", "jshelpers")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	for name, source := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runtime := New()
+	runtime.Timeout = 200 * time.Millisecond
+
+	if err := runtime.LoadDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	return runtime, dir
+}
+
+// renderWith Renders handlebars template with helpers of runtime
+func renderWith(runtime *Runtime, source string, data interface{}) (string, error) {
+	engine := &render.HandlebarsEngine{Helpers: runtime.Helpers()}
+	return engine.Render("report.hbs", source, data)
+}
+
+func TestLoadDirRegistersHelpers(t *testing.T) {
+	runtime, _ := loadRuntime(t, map[string]string{
+		"a.js": `module.exports.register = function (Handlebars) {
+			Handlebars.registerHelper('upper', function (value) { return String(value).toUpperCase(); });
+			Handlebars.registerHelper({first: function (list) { return list[0]; }});
+		};`,
+		"b.js": `var Handlebars = require('handlebars');
+			module.exports = {
+				twice: function (value) { return value + value; },
+				version: '1.0'
+			};`,
+	})
+
+	if names := runtime.Names(); !reflect.DeepEqual(names, []string{"first", "twice", "upper"}) {
+		t.Errorf("helpers are %q", names)
+	}
+
+	got, err := renderWith(runtime, `{{upper title}} {{first rows}} {{twice "ab"}}`,
+		map[string]interface{}{"title": "report", "rows": []interface{}{"one", "two"}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != "REPORT one abab" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestHelpersCalledLikeHandlebarsJs(t *testing.T) {
+	runtime, _ := loadRuntime(t, map[string]string{
+		"helpers.js": `module.exports.register = function (Handlebars) {
+			Handlebars.registerHelper('args', function () {
+				var options = arguments[arguments.length - 1];
+				var params = Array.prototype.slice.call(arguments, 0, -1);
+				return options.name + '(' + params.join(',') + ')' + (options.hash.sep || '');
+			});
+			Handlebars.registerHelper('bold', function (text) {
+				return new Handlebars.SafeString('<b>' + Handlebars.escapeExpression(text) + '</b>');
+			});
+			Handlebars.registerHelper('tag', function (text) { return '<i>' + text + '</i>'; });
+			Handlebars.registerHelper('each2', function (list, options) {
+				return list.map(function (item) { return options.fn(item); }).join(';');
+			});
+			Handlebars.registerHelper('own', function () { return this.title; });
+			Handlebars.registerHelper('kind', function (value) { return value === null ? 'null' : typeof value; });
+		};`,
+	})
+
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"arguments of every call", `{{args}} {{args a}} {{args a b "c"}} {{args b sep="!"}}`,
+			"args() args(1) args(1,2,c) args(2)!"},
+		{"safe string is not escaped", `{{bold "<x>"}}`, "<b>&lt;x&gt;</b>"},
+		{"string is escaped", `{{tag "x"}}`, "&lt;i&gt;x&lt;/i&gt;"},
+		{"block", `{{#each2 rows}}[{{this}}]{{/each2}}`, "[a];[b]"},
+		{"context is this", `{{own}}`, "Report"},
+		{"missing path is undefined", `{{kind missing.path}} {{kind note}} {{kind a}}`, "undefined null number"},
+	}
+
+	data := map[string]interface{}{"a": 1, "b": 2, "title": "Report", "rows": []interface{}{"a", "b"},
+		"note": nil}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := renderWith(runtime, test.source, data)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHelperErrors(t *testing.T) {
+	runtime, dir := loadRuntime(t, map[string]string{
+		"helpers.js": `module.exports = {
+			boom: function () { throw new Error('no data'); },
+			loop: function () { while (true) {} },
+			ok: function () { return 'ok'; }
+		};`,
+	})
+
+	tpl := raymond.MustParse("{{boom}}")
+	tpl.RegisterHelper("boom", func(options *raymond.Options) interface{} {
+		return runtime.call("boom", nil, options)
+	})
+
+	// raymond returns error panics of helpers as is
+	if _, err := tpl.Exec(nil); !isHelperError(err, "boom", filepath.Join(dir, "helpers.js")) {
+		t.Errorf("error is %#v, want HelperError", err)
+	}
+
+	_, err := renderWith(runtime, "{{ok}}\n  {{boom}}", nil)
+
+	if err == nil || !strings.HasPrefix(err.Error(), "report.hbs:2:5: javascript helper boom (") ||
+		!strings.Contains(err.Error(), "no data") {
+		t.Errorf("error is %v, want thrown error at call position", err)
+	}
+
+	_, err = renderWith(runtime, "{{loop}}", nil)
+
+	if err == nil || !strings.Contains(err.Error(), "loop timed out after 200ms") {
+		t.Errorf("error is %v, want timeout", err)
+	}
+
+	if got, err := renderWith(runtime, "{{ok}}", nil); err != nil || got != "ok" {
+		t.Errorf("helper after timeout returned %q, %v", got, err)
+	}
+}
+
+// isHelperError Checks err is HelperError of helper from file
+func isHelperError(err error, helper string, file string) bool {
+	helperErr, ok := err.(*HelperError)
+	return ok && helperErr.Helper == helper && helperErr.File == file
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		message string
+	}{
+		{"thrown error", `throw new Error('broken file');`, "broken file"},
+		{"syntax error", `module.exports = {`, "helpers.js"},
+		{"endless loop", `while (true) {}`, "timed out after"},
+		{"unsupported require", `require('fs');`, "require('fs') is not supported"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "jshelpers")
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer os.RemoveAll(dir)
+			filename := filepath.Join(dir, "helpers.js")
+
+			if err := ioutil.WriteFile(filename, []byte(test.source), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			runtime := New()
+			runtime.Timeout = 100 * time.Millisecond
+			err = runtime.LoadFile(filename)
+
+			if !isHelperError(err, "", filename) || !strings.Contains(err.Error(), test.message) {
+				t.Errorf("error is %#v, want HelperError containing %q", err, test.message)
+			}
+		})
+	}
+}
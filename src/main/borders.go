@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"github.com/icewind666/html-to-excel-renderer/src/css"
+	"github.com/icewind666/html-to-excel-renderer/src/types"
+	"github.com/jbowtie/gokogiri/xml"
+	log "github.com/sirupsen/logrus"
+	"strconv"
+	"strings"
+)
+
+// BorderAttrName Border shorthand style and html table attribute name
+const BorderAttrName = "border"
+
+// defaultBorderWidth Width of border without width in css pixels. Browsers use medium (3px),
+// thin is closer to excel grid lines
+const defaultBorderWidth = 1.0
+
+// noBorder Initial border of cells
+var noBorder = types.Border{Style: "none", Width: defaultBorderWidth}
+
+// borderSides Border sides in css order (types.BorderTop, types.BorderRight...)
+var borderSides = []string{"top", "right", "bottom", "left"}
+
+// borderWidthKeywords Border width keywords in css pixels
+var borderWidthKeywords = map[string]float64{
+	"thin":   1,
+	"medium": 3,
+	"thick":  5,
+}
+
+// borderStyleKeywords Css border styles
+var borderStyleKeywords = map[string]bool{
+	"none":   true,
+	"hidden": true,
+	"dotted": true,
+	"dashed": true,
+	"solid":  true,
+	"double": true,
+	"groove": true,
+	"ridge":  true,
+	"inset":  true,
+	"outset": true,
+}
+
+// applyBorderDeclaration Sets borders of style from border property: border, border-top, border-width,
+// border-top-color... Other border properties (border-collapse, border-radius) are ignored.
+// Invalid values are ignored with a warning
+func applyBorderDeclaration(style *types.HtmlStyle, property string, value string, fontPixels float64) {
+	sides := []int{types.BorderTop, types.BorderRight, types.BorderBottom, types.BorderLeft}
+	var parts []string
+
+	if rest := strings.TrimPrefix(property, BorderAttrName); rest != "" {
+		parts = strings.Split(strings.TrimPrefix(rest, "-"), "-")
+	}
+
+	if len(parts) > 0 {
+		for i, side := range borderSides {
+			if parts[0] == side {
+				sides, parts = []int{i}, parts[1:]
+				break
+			}
+		}
+	}
+
+	if len(parts) > 1 || len(parts) == 1 && parts[0] != "width" && parts[0] != "style" && parts[0] != "color" {
+		return // border-collapse, border-spacing, border-radius...
+	}
+
+	if len(parts) == 0 {
+		border, err := parseBorder(value, fontPixels)
+
+		if err != nil {
+			log.WithError(err).Warnf("Border of %s ignored", property)
+			return
+		}
+
+		for _, side := range sides {
+			style.Borders[side] = border
+		}
+
+		return
+	}
+
+	values := [4]string{value, value, value, value}
+
+	if len(sides) > 1 {
+		var ok bool
+
+		if values, ok = css.ExpandSides(css.SplitValue(value)); !ok {
+			log.Warnf("Border of %s ignored: 1-4 values expected in %q", property, value)
+			return
+		}
+	}
+
+	borders := style.Borders // invalid value of any side ignores whole declaration
+
+	for _, side := range sides {
+		if err := setBorderPart(&borders[side], parts[0], values[side], fontPixels); err != nil {
+			log.WithError(err).Warnf("Border of %s ignored", property)
+			return
+		}
+	}
+
+	style.Borders = borders
+}
+
+// setBorderPart Sets width, style or color of border
+func setBorderPart(border *types.Border, part string, value string, fontPixels float64) error {
+	switch part {
+	case "width":
+		width, err := parseBorderWidth(value, fontPixels)
+
+		if err != nil {
+			return err
+		}
+
+		border.Width = width
+	case "style":
+		if !borderStyleKeywords[strings.ToLower(value)] {
+			return fmt.Errorf("unknown border style %q", value)
+		}
+
+		border.Style = strings.ToLower(value)
+	case "color":
+		color, err := css.ExcelColor(value)
+
+		if err != nil {
+			return err
+		}
+
+		border.Color = color
+	}
+
+	return nil
+}
+
+// parseBorder Parses border shorthand "1px solid red". Missing parts are default: none style,
+// default width and font color
+func parseBorder(value string, fontPixels float64) (types.Border, error) {
+	border := noBorder
+
+	for _, token := range css.SplitValue(value) {
+		if borderStyleKeywords[strings.ToLower(token)] {
+			border.Style = strings.ToLower(token)
+		} else if width, err := parseBorderWidth(token, fontPixels); err == nil {
+			border.Width = width
+		} else if color, err := css.ExcelColor(token); err == nil {
+			border.Color = color
+		} else {
+			return types.Border{}, fmt.Errorf("invalid border %q", value)
+		}
+	}
+
+	return border, nil
+}
+
+// parseBorderWidth Parses border width: thin, medium, thick or length
+func parseBorderWidth(value string, fontPixels float64) (float64, error) {
+	if width, ok := borderWidthKeywords[strings.ToLower(value)]; ok {
+		return width, nil
+	}
+
+	length, err := css.ParseLength(value)
+
+	if err != nil || length.Unit == "%" || length.Value < 0 {
+		return 0, fmt.Errorf("invalid border width %q", value)
+	}
+
+	return length.Pixels(fontPixels, 0), nil
+}
+
+// tableBorderHints Returns declarations of html table border attribute applied to its cells: cells of table
+// with non zero border have 1px border like in browsers (attribute without value is 1)
+func tableBorderHints(table xml.Node) []css.Declaration {
+	attr := table.Attribute(BorderAttrName)
+
+	if attr == nil {
+		return nil
+	}
+
+	if width, err := strconv.Atoi(strings.TrimSpace(attr.Value())); err == nil && width <= 0 {
+		return nil
+	}
+
+	return []css.Declaration{{Property: BorderAttrName, Value: "1px solid"}}
+}
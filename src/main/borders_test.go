@@ -0,0 +1,72 @@
+package main
+
+import (
+	"github.com/icewind666/html-to-excel-renderer/src/generator"
+	"github.com/icewind666/html-to-excel-renderer/src/types"
+	"testing"
+)
+
+func TestExtractStylesBorders(t *testing.T) {
+	solid := func(width float64, color string) types.Border {
+		return types.Border{Style: "solid", Width: width, Color: color}
+	}
+
+	tests := []struct {
+		style   string
+		borders [4]types.Border // top, right, bottom, left
+	}{
+		{"border: 2px solid red",
+			[4]types.Border{solid(2, "#FF0000"), solid(2, "#FF0000"), solid(2, "#FF0000"), solid(2, "#FF0000")}},
+		{"border: solid; border-width: 1px 2px 3px 4px",
+			[4]types.Border{solid(1, ""), solid(2, ""), solid(3, ""), solid(4, "")}},
+		{"border: solid; border-width: thin thick",
+			[4]types.Border{solid(1, ""), solid(5, ""), solid(1, ""), solid(5, "")}},
+		{"font-size: 16px; border-bottom: medium dashed #00f; border-left: 0.5em double",
+			[4]types.Border{noBorder, noBorder, {Style: "dashed", Width: 3, Color: "#0000FF"}, {Style: "double", Width: 8}}},
+		{"border: 1px solid; border-top-width: 3px; border-right-style: none; border-left-color: green",
+			[4]types.Border{solid(3, ""), {Style: "none", Width: 1}, solid(1, ""), solid(1, "#008000")}},
+		{"border: 1px solid; border-width: 1px 2px 3px 4px 5px; border-top: 1px wavy; border-left-width: -1px",
+			[4]types.Border{solid(1, ""), solid(1, ""), solid(1, ""), solid(1, "")}}, // invalid values are ignored
+		{"border-top: 1px solid; border-radius: 4px; border-collapse: collapse",
+			[4]types.Border{solid(1, ""), noBorder, noBorder, noBorder}},
+	}
+
+	for _, test := range tests {
+		if borders := ExtractStylesString(test.style).Borders; borders != test.borders {
+			t.Errorf("%q borders are %+v, want %+v", test.style, borders, test.borders)
+		}
+	}
+}
+
+func TestBordersToExcelizeStringBySideWidth(t *testing.T) {
+	style := ExtractStylesString("color: #333; border-style: solid; border-width: 0.5px 1px 3px 4px; " +
+		"border-left-color: red")
+	want := `[{"type": "top","style": 7,"color":"#333333"},{"type": "right","style": 1,"color":"#333333"},` +
+		`{"type": "bottom","style": 2,"color":"#333333"},{"type": "left","style": 5,"color":"#FF0000"}]`
+
+	if borders := generator.BordersToExcelizeString(style); borders != want {
+		t.Errorf("borders are %s, want %s", borders, want)
+	}
+
+	style = ExtractStylesString("border: 1px dashed; border-bottom: 2px dotted; border-right: none; border-left: 0 solid")
+	want = `[{"type": "top","style": 3,"color":"#000000"},{"type": "bottom","style": 12,"color":"#000000"}]`
+
+	if borders := generator.BordersToExcelizeString(style); borders != want {
+		t.Errorf("borders are %s, want %s", borders, want)
+	}
+}
+
+func TestTableBorderHints(t *testing.T) {
+	tests := map[string]int{
+		`<table><tr><td>a</td></tr></table>`:            0,
+		`<table border><tr><td>a</td></tr></table>`:     1,
+		`<table border="2"><tr><td>a</td></tr></table>`: 1,
+		`<table border="0"><tr><td>a</td></tr></table>`: 0,
+	}
+
+	for html, want := range tests {
+		if hints := tableBorderHints(parseTables(t, html)[0]); len(hints) != want {
+			t.Errorf("%s border hints are %v, want %d", html, hints, want)
+		}
+	}
+}
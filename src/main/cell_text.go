@@ -0,0 +1,400 @@
+package main
+
+import (
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/icewind666/html-to-excel-renderer/src/css"
+	"github.com/icewind666/html-to-excel-renderer/src/generator"
+	"github.com/icewind666/html-to-excel-renderer/src/types"
+	"github.com/jbowtie/gokogiri/xml"
+	"github.com/jbowtie/gokogiri/xpath"
+	"strconv"
+	"strings"
+)
+
+// XpathLi Items of list
+var XpathLi = xpath.Compile("./li")
+
+// WhiteSpaceStyleAttr White space handling attribute name
+const WhiteSpaceStyleAttr = "white-space"
+
+// DisplayStyleAttr Display attribute name. Elements with display: none are skipped, block elements start new lines
+const DisplayStyleAttr = "display"
+
+// White space modes
+const (
+	WhiteSpaceNormal  = "normal"   // spaces and line breaks are collapsed
+	WhiteSpaceNowrap  = "nowrap"   // collapsed like normal
+	WhiteSpacePre     = "pre"      // spaces and line breaks are preserved
+	WhiteSpacePreWrap = "pre-wrap" // preserved like pre
+	WhiteSpacePreLine = "pre-line" // spaces are collapsed, line breaks are preserved
+)
+
+// blockElements Elements starting and ending lines of cell text
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "caption": true, "center": true,
+	"dd": true, "details": true, "dialog": true, "div": true, "dl": true, "dt": true, "fieldset": true,
+	"figcaption": true, "figure": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "hgroup": true, "hr": true, "legend": true,
+	"li": true, "listing": true, "main": true, "nav": true, "ol": true, "p": true, "plaintext": true,
+	"pre": true, "section": true, "summary": true, "table": true, "tr": true, "ul": true, "xmp": true,
+}
+
+// preElements Elements preserving white space by default
+var preElements = map[string]bool{"pre": true, "textarea": true, "listing": true, "xmp": true, "plaintext": true}
+
+// skippedElements Elements without text content
+var skippedElements = map[string]bool{
+	"script": true, "style": true, "template": true, "head": true, "title": true, "img": true, "noscript": true,
+}
+
+// listBullets Bullets of unordered list items by nesting level
+var listBullets = []string{"•", "◦", "▪"}
+
+// cellTextBuilder Builds cell text from html content like browsers render it: block elements and <br>
+// start new lines, white space is collapsed according to white-space style. Text is split to runs of
+// the same font and vertical alignment set by styles of inline elements
+type cellTextBuilder struct {
+	matcher    *css.Matcher
+	text       strings.Builder
+	runs       []*textRun
+	font       *excelize.Font // font of text written
+	vertAlign  string         // vertical alignment of text written: superscript, subscript or baseline when empty
+	spaceFont  *excelize.Font // font of pending collapsed space
+	spaceAlign string         // vertical alignment of pending collapsed space
+	empty      bool           // nothing written yet, leading spaces and line breaks are dropped
+	lineStart  bool           // list marker is written, spaces after it are dropped
+	space      bool           // collapsed space is pending
+	breaks     int            // line breaks pending
+	lists      []*listCounter
+}
+
+// textRun Text written with one font and vertical alignment
+type textRun struct {
+	font      *excelize.Font
+	vertAlign string
+	text      strings.Builder
+}
+
+// richTextRuns Rich text runs of cell text
+type richTextRuns []generator.RichTextRun
+
+// listCounter Numbering of list items
+type listCounter struct {
+	ordered bool
+	next    int
+	step    int    // -1 for reversed lists
+	kind    string // type attribute of <ol>: 1, a, A, i, I
+}
+
+// cellText Returns text of cell and its rich text runs. Runs are nil when all text has cell font.
+// Declarations are computed declarations of the cell, style is cell style made of them
+func cellText(cell xml.Node, declarations []css.Declaration, style *types.HtmlStyle,
+	matcher *css.Matcher) (string, richTextRuns) {
+	cellFont := generator.ExcelizeFont(style)
+	builder := &cellTextBuilder{matcher: matcher, font: cellFont, empty: true}
+	builder.writeChildren(cell, whiteSpace(declarations, cell.Name(), WhiteSpaceNormal), declarations)
+	return builder.text.String(), builder.richText(cellFont)
+}
+
+// whiteSpace Returns white-space mode of element with given own declarations. Elements without
+// white-space style inherit parent mode
+func whiteSpace(declarations []css.Declaration, name string, parent string) string {
+	if declaration, ok := css.LastDeclaration(declarations, WhiteSpaceStyleAttr); ok {
+		switch mode := strings.ToLower(declaration.Value); mode {
+		case WhiteSpaceNormal, WhiteSpaceNowrap, WhiteSpacePre, WhiteSpacePreWrap, WhiteSpacePreLine:
+			return mode
+		case "break-spaces":
+			return WhiteSpacePreWrap
+		}
+	}
+
+	if preElements[name] {
+		return WhiteSpacePre
+	}
+
+	return parent
+}
+
+// writeChildren Writes text of child nodes. Declarations are computed declarations of node
+func (b *cellTextBuilder) writeChildren(node xml.Node, mode string, declarations []css.Declaration) {
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		switch child.NodeType() {
+		case xml.XML_TEXT_NODE, xml.XML_CDATA_SECTION_NODE:
+			b.writeText(child.Content(), mode)
+		case xml.XML_ELEMENT_NODE:
+			b.writeElement(child, mode, declarations)
+		}
+	}
+}
+
+// writeElement Writes text of element with font of its computed declarations
+func (b *cellTextBuilder) writeElement(node xml.Node, parentMode string, parentDeclarations []css.Declaration) {
+	name := strings.ToLower(node.Name())
+
+	if skippedElements[name] {
+		return
+	}
+
+	if name == "br" {
+		b.breaks++
+		return
+	}
+
+	own := b.matcher.Declarations(node)
+	isBlock := blockElements[name]
+
+	if declaration, ok := css.LastDeclaration(own, DisplayStyleAttr); ok {
+		switch display := strings.ToLower(declaration.Value); display {
+		case "none":
+			return
+		case "inline", "inline-block", "inline-flex", "inline-grid", "contents":
+			isBlock = false
+		default:
+			isBlock = true
+		}
+	}
+
+	mode := whiteSpace(own, name, parentMode)
+	declarations := parentDeclarations
+	parentFont, parentAlign := b.font, b.vertAlign
+
+	if defaults := elementDeclarations(node, name); len(own) > 0 || len(defaults) > 0 {
+		elementOwn := append(defaults, own...)
+		declarations = css.Inherit(parentDeclarations, elementOwn)
+		b.font = generator.ExcelizeFont(ExtractStylesDeclarations(declarations, 0))
+		b.vertAlign = runVertAlign(elementOwn, b.vertAlign)
+	}
+
+	if isBlock {
+		b.blockBreak()
+	}
+
+	switch name {
+	case "ul", "ol":
+		b.lists = append(b.lists, newListCounter(node))
+		b.writeChildren(node, mode, declarations)
+		b.lists = b.lists[:len(b.lists)-1]
+	case "li":
+		b.writeListMarker(node)
+		b.writeChildren(node, mode, declarations)
+	default:
+		b.writeChildren(node, mode, declarations)
+	}
+
+	if isBlock {
+		b.blockBreak()
+	}
+
+	b.font, b.vertAlign = parentFont, parentAlign
+}
+
+// runVertAlign Returns vertical alignment of text of inline element with given own declarations:
+// super and sub are superscript and subscript, baseline resets it, other values keep parent alignment
+func runVertAlign(own []css.Declaration, parent string) string {
+	declaration, ok := css.LastDeclaration(own, TextVerticalAlignStyleAttr)
+
+	if !ok {
+		return parent
+	}
+
+	switch strings.ToLower(strings.TrimSpace(declaration.Value)) {
+	case "super":
+		return generator.VertAlignSuperscript
+	case "sub":
+		return generator.VertAlignSubscript
+	case "baseline":
+		return ""
+	}
+
+	return parent
+}
+
+// writeText Writes text node content collapsing white space according to mode
+func (b *cellTextBuilder) writeText(text string, mode string) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	for _, r := range text {
+		switch {
+		case r == '\n' && mode != WhiteSpaceNormal && mode != WhiteSpaceNowrap:
+			b.space = false
+			b.breaks++
+		case isCollapsibleSpace(r) && mode != WhiteSpacePre && mode != WhiteSpacePreWrap:
+			if !b.space {
+				b.space, b.spaceFont, b.spaceAlign = true, b.font, b.vertAlign
+			}
+		default:
+			if r == '\u00a0' {
+				r = ' ' // &nbsp;
+			}
+
+			b.flush()
+			b.write(string(r), b.font, b.vertAlign)
+			b.empty, b.lineStart = false, false
+		}
+	}
+}
+
+// write Appends text written with font and vertical alignment to cell text and its runs
+func (b *cellTextBuilder) write(text string, font *excelize.Font, vertAlign string) {
+	b.text.WriteString(text)
+
+	if last := len(b.runs) - 1; last >= 0 && b.runs[last].vertAlign == vertAlign &&
+		(b.runs[last].font == font || *b.runs[last].font == *font) {
+		b.runs[last].text.WriteString(text)
+		return
+	}
+
+	run := &textRun{font: font, vertAlign: vertAlign}
+	run.text.WriteString(text)
+	b.runs = append(b.runs, run)
+}
+
+// richText Returns rich text runs of cell text, nil when all runs have cell font on the baseline
+func (b *cellTextBuilder) richText(cellFont *excelize.Font) richTextRuns {
+	if len(b.runs) == 0 || len(b.runs) == 1 && *b.runs[0].font == *cellFont && b.runs[0].vertAlign == "" {
+		return nil
+	}
+
+	result := make(richTextRuns, 0, len(b.runs))
+
+	for _, run := range b.runs {
+		result = append(result, generator.RichTextRun{
+			RichTextRun: excelize.RichTextRun{Font: run.font, Text: run.text.String()},
+			VertAlign:   run.vertAlign,
+		})
+	}
+
+	return result
+}
+
+// blockBreak Ends current line unless it is empty
+func (b *cellTextBuilder) blockBreak() {
+	if b.breaks == 0 {
+		b.breaks = 1
+	}
+
+	b.space = false
+}
+
+// flush Writes pending line breaks or collapsed space before content
+func (b *cellTextBuilder) flush() {
+	if b.empty {
+		b.breaks, b.space = 0, false
+		return
+	}
+
+	if b.breaks > 0 {
+		b.write(strings.Repeat("\n", b.breaks), b.font, "")
+	} else if b.space && !b.lineStart {
+		b.write(" ", b.spaceFont, b.spaceAlign)
+	}
+
+	b.breaks, b.space = 0, false
+}
+
+// writeListMarker Writes indent and bullet or number of list item
+func (b *cellTextBuilder) writeListMarker(item xml.Node) {
+	if len(b.lists) == 0 {
+		return // <li> outside of list
+	}
+
+	b.flush()
+	level := len(b.lists) - 1
+	list := b.lists[level]
+	marker := strings.Repeat("  ", level)
+	b.empty, b.lineStart = false, true
+
+	if list.ordered {
+		if value, err := strconv.Atoi(strings.TrimSpace(item.Attr("value"))); err == nil {
+			list.next = value
+		}
+
+		marker += listNumber(list.next, list.kind) + ". "
+		list.next += list.step
+	} else {
+		marker += listBullets[minInt(level, len(listBullets)-1)] + " "
+	}
+
+	b.write(marker, b.font, "")
+}
+
+// newListCounter Returns counter of <ul> or <ol> list honoring start, reversed and type attributes
+func newListCounter(list xml.Node) *listCounter {
+	counter := &listCounter{ordered: strings.ToLower(list.Name()) == "ol", next: 1, step: 1, kind: list.Attr("type")}
+
+	if !counter.ordered {
+		return counter
+	}
+
+	if list.Attribute("reversed") != nil {
+		items, _ := list.Search(XpathLi)
+		counter.step, counter.next = -1, len(items)
+	}
+
+	if start, err := strconv.Atoi(strings.TrimSpace(list.Attr("start"))); err == nil {
+		counter.next = start
+	}
+
+	return counter
+}
+
+// listNumber Returns number of ordered list item in list type: 1 (decimal), a, A (letters), i, I (roman)
+func listNumber(number int, kind string) string {
+	if number <= 0 || kind == "" || kind == "1" {
+		return strconv.Itoa(number)
+	}
+
+	switch kind {
+	case "a", "A":
+		letters := ""
+
+		for n := number; n > 0; n = (n - 1) / 26 {
+			letters = string(rune('a'+(n-1)%26)) + letters
+		}
+
+		if kind == "A" {
+			return strings.ToUpper(letters)
+		}
+
+		return letters
+	case "i", "I":
+		roman := romanNumber(number)
+
+		if kind == "i" {
+			return strings.ToLower(roman)
+		}
+
+		return roman
+	}
+
+	return strconv.Itoa(number)
+}
+
+// romanNumber Returns roman number
+func romanNumber(number int) string {
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	symbols := []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
+	var result strings.Builder
+
+	for i, value := range values {
+		for number >= value {
+			result.WriteString(symbols[i])
+			number -= value
+		}
+	}
+
+	return result.String()
+}
+
+// isCollapsibleSpace Checks rune is html white space (&nbsp; is not)
+func isCollapsibleSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f'
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
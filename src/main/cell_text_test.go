@@ -0,0 +1,125 @@
+package main
+
+import (
+	"github.com/icewind666/html-to-excel-renderer/src/css"
+	"reflect"
+	"testing"
+)
+
+// cellTextOf Returns text and rich text runs of the first cell of html table
+func cellTextOf(t *testing.T, html string) (string, richTextRuns) {
+	table := parseTables(t, html)[0]
+	cells, _ := table.Search(".//td")
+	matcher := css.NewMatcher(loadStylesheet(table.MyDocument().Root()))
+	declarations := matcher.Declarations(cells[0])
+	text, runs := cellText(cells[0], declarations, ExtractStylesDeclarations(declarations, 0), matcher)
+	return text, runs
+}
+
+func TestCellTextLines(t *testing.T) {
+	tests := []struct {
+		cell string
+		want string
+	}{
+		{`<td>one<br>two<br/><br>three</td>`, "one\ntwo\n\nthree"},
+		{`<td><br>one<br></td>`, "one"}, // leading and trailing breaks are dropped
+		{`<td>one<p>two</p><div>three</div>four</td>`, "one\ntwo\nthree\nfour"},
+		{`<td><div><p>one</p></div><div>two</div></td>`, "one\ntwo"},
+		{`<td>a&nbsp;&nbsp;b</td>`, "a  b"},
+		{`<td>one<span style="display: none">hidden</span> <span style="display: block">two</span></td>`, "one\ntwo"},
+	}
+
+	for _, test := range tests {
+		if text, _ := cellTextOf(t, `<table><tr>`+test.cell+`</tr></table>`); text != test.want {
+			t.Errorf("%s text is %q, want %q", test.cell, text, test.want)
+		}
+	}
+}
+
+func TestCellTextLists(t *testing.T) {
+	tests := []struct {
+		cell string
+		want string
+	}{
+		{`<td><ul><li>one</li><li> two </li></ul></td>`, "• one\n• two"},
+		{`<td>Items:<ol><li>one<li>two</ol>done</td>`, "Items:\n1. one\n2. two\ndone"},
+		{`<td><ol start="3" type="a"><li>c</li><li>d</li></ol></td>`, "c. c\nd. d"},
+		{`<td><ol reversed type="I"><li>b</li><li>a</li></ol></td>`, "II. b\nI. a"},
+		{`<td><ol><li value="5">e</li><li>f</li></ol></td>`, "5. e\n6. f"},
+		{`<td><ul><li>one<ul><li>nested</li></ul></li><li>two</li></ul></td>`, "• one\n  ◦ nested\n• two"},
+	}
+
+	for _, test := range tests {
+		if text, _ := cellTextOf(t, `<table><tr>`+test.cell+`</tr></table>`); text != test.want {
+			t.Errorf("%s text is %q, want %q", test.cell, text, test.want)
+		}
+	}
+}
+
+func TestCellTextWhiteSpace(t *testing.T) {
+	tests := []struct {
+		cell string
+		want string
+	}{
+		{"<td>  one \n\t two  </td>", "one two"},
+		{"<td style=\"white-space: normal\">one\n  two</td>", "one two"},
+		{"<td style=\"white-space: nowrap\">one\n  two</td>", "one two"},
+		{"<td style=\"white-space: pre\">one\n  two</td>", "one\n  two"},
+		{"<td style=\"white-space: pre-wrap\">one  two</td>", "one  two"},
+		{"<td style=\"white-space: pre-line\">one  \n  two</td>", "one\ntwo"},
+		{"<td>one <pre>a  b\nc</pre></td>", "one\na  b\nc"},
+		{"<td style=\"white-space: pre\">one <span style=\"white-space: normal\">a   b</span></td>", "one a b"},
+	}
+
+	for _, test := range tests {
+		if text, _ := cellTextOf(t, `<table><tr>`+test.cell+`</tr></table>`); text != test.want {
+			t.Errorf("%q text is %q, want %q", test.cell, text, test.want)
+		}
+	}
+}
+
+func TestCellTextRuns(t *testing.T) {
+	tests := []struct {
+		html string
+		runs int
+	}{
+		{`<table><tr><td>plain <span>text</span></td></tr></table>`, 0},
+		{`<table><tr><td style="font-weight: bold">bold</td></tr></table>`, 0},
+		{`<table><tr><td>a <b>bold</b> and <i>italic</i></td></tr></table>`, 4},
+		{`<style>.red { color: red }</style><table><tr><td>a <span class="red">red</span></td></tr></table>`, 2},
+	}
+
+	for _, test := range tests {
+		if _, runs := cellTextOf(t, test.html); len(runs) != test.runs {
+			t.Errorf("%s has %d runs, want %d", test.html, len(runs), test.runs)
+		}
+	}
+}
+
+func TestCellTextSuperscriptAndSubscript(t *testing.T) {
+	tests := []struct {
+		cell string
+		want []string // text and vertical alignment of runs
+	}{
+		{`<td>m<sup>2</sup></td>`, []string{"m", "", "2", "superscript"}},
+		{`<td>H<sub>2</sub>O</td>`, []string{"H", "", "2", "subscript", "O", ""}},
+		{`<td><sup>1</sup></td>`, []string{"1", "superscript"}},
+		{`<td>x<sup>a <sub>b</sub></sup></td>`, []string{"x", "", "a ", "superscript", "b", "subscript"}},
+		{`<td>a<span style="vertical-align: super">b<span style="vertical-align: baseline">c</span></span></td>`,
+			[]string{"a", "", "b", "superscript", "c", ""}},
+		{`<td>a <span style="vertical-align: middle">b</span></td>`, nil},
+	}
+
+	for _, test := range tests {
+		_, runs := cellTextOf(t, `<table><tr>`+test.cell+`</tr></table>`)
+		var got []string
+
+		for _, run := range runs {
+			got = append(got, run.Text, run.VertAlign)
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s runs are %q, want %q", test.cell, got, test.want)
+		}
+	}
+}
@@ -23,18 +23,27 @@ const FontSizeStyleAttr = "font-size"
 // FontWeightStyleAttr Font weight attribute name
 const FontWeightStyleAttr = "font-weight"
 
+// FontStyleAttr Font style (italic) attribute name
+const FontStyleAttr = "font-style"
+
+// FontFamilyStyleAttr Font family attribute name
+const FontFamilyStyleAttr = "font-family"
+
+// FontStyleShorthandAttr Font shorthand attribute name
+const FontStyleShorthandAttr = "font"
+
+// TextDecorationStyleAttr Text decoration (underline, line-through) attribute name
+const TextDecorationStyleAttr = "text-decoration"
+
+// TextDecorationLineStyleAttr Text decoration line attribute name
+const TextDecorationLineStyleAttr = "text-decoration-line"
+
 // BorderStyleAttr Border style attribute name
 const BorderStyleAttr = "border-style"
 
-// BorderStyleAttrValue Border style attribute value
-const BorderStyleAttrValue = "solid"
-
-// BorderInheritanceStyleAttr Border inheritance style attribute name
+// BorderInheritanceStyleAttr Border inheritance style attribute name. Legacy alias of border-style
 const BorderInheritanceStyleAttr = "border-inheritance-type"
 
-// BorderInheritanceStyleAttrValue Border inheritance style attribute value
-const BorderInheritanceStyleAttrValue = "solid"
-
 // WidthStyleAttr Width attribute name
 const WidthStyleAttr = "width"
 
@@ -61,6 +70,16 @@ const TextVerticalAlignStyleBottom = "bottom"
 const ValueTypeAttrName = "cell-type"
 const BackgroundColorAttrName = "background-color"
 
+// BackgroundStyleAttr Background shorthand attribute name, only its color is used
+const BackgroundStyleAttr = "background"
+
+// DefaultFontSize Font size of cells without font-size style in points (excel default font size).
+// Em lengths of such cells are relative to it
+const DefaultFontSize = 11.0
+
+// ColorStyleAttr Font color attribute name
+const ColorStyleAttr = "color"
+
 const(
 	FloatValueType types.ValueType = "float"
 	BooleanValueType types.ValueType = "bool"
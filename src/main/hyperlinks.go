@@ -0,0 +1,137 @@
+package main
+
+import (
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/icewind666/html-to-excel-renderer/src/generator"
+	"github.com/jbowtie/gokogiri/xml"
+	"github.com/jbowtie/gokogiri/xpath"
+	log "github.com/sirupsen/logrus"
+	"net/url"
+	"strings"
+)
+
+// HrefAttrName Link target attribute name
+const HrefAttrName = "href"
+
+// IdAttrName Element id attribute name. Tables and cells with id are targets of "#id" links
+const IdAttrName = "id"
+
+// XpathLink Links of cell
+var XpathLink = xpath.Compile(".//a[@href]")
+
+// linkStyle Default style of links, like excel hyperlink cell style. Styles of links override it
+const linkStyle = "color: #0563C1; text-decoration: underline"
+
+// cellLocation Excel cell on sheet
+type cellLocation struct {
+	Sheet string
+	Row   int
+	Col   int
+}
+
+// internalLink Cell linked to place in workbook: "Sheet!A1" or id of table or cell
+type internalLink struct {
+	cell   cellLocation
+	target string
+}
+
+// workbookLinks Targets of internal links (tables and cells with id) and cells linked to them.
+// Internal links are set when all tables are written, targets may be written after links
+type workbookLinks struct {
+	targets map[string]cellLocation
+	links   []internalLink
+}
+
+// newWorkbookLinks Returns empty links of workbook
+func newWorkbookLinks() *workbookLinks {
+	return &workbookLinks{targets: make(map[string]cellLocation)}
+}
+
+// AddTarget Registers element written at row and column of sheet as target of links to its id.
+// Elements without id are ignored, the first element with id wins like in browsers
+func (l *workbookLinks) AddTarget(node xml.Node, sheet string, row int, col int) {
+	id := node.Attr(IdAttrName)
+
+	if _, exists := l.targets[id]; id == "" || exists {
+		return
+	}
+
+	l.targets[id] = cellLocation{Sheet: sheet, Row: row, Col: col}
+}
+
+// SetCellLink Links current cell to href of the first link in cell (excel cell has one link).
+// Urls (https:, mailto:...) are set at once, "#Sheet!A1" and "#id" links are set by Resolve
+func (l *workbookLinks) SetCellLink(cell xml.Node, generator *generator.ExcelizeGenerator) {
+	anchors, _ := cell.Search(XpathLink)
+
+	if len(anchors) == 0 {
+		return
+	}
+
+	if len(anchors) > 1 {
+		log.Debugf("Cell %s has %d links. Only the first one is set", cellName(generator.CurrentRow,
+			generator.CurrentCol), len(anchors))
+	}
+
+	href := strings.TrimSpace(anchors[0].Attr(HrefAttrName))
+
+	switch {
+	case href == "" || href == "#" || strings.HasPrefix(strings.ToLower(href), "javascript:"):
+		return
+	case strings.HasPrefix(href, "#"):
+		target, err := url.PathUnescape(href[1:])
+
+		if err != nil {
+			target = href[1:]
+		}
+
+		location := cellLocation{Sheet: generator.CurrentSheet, Row: generator.CurrentRow, Col: generator.CurrentCol}
+		l.links = append(l.links, internalLink{cell: location, target: target})
+	default:
+		generator.SetCellExternalLink(href)
+	}
+}
+
+// Resolve Sets internal links of cells. Links to missing sheets, cells or ids are skipped with a warning
+func (l *workbookLinks) Resolve(generator *generator.ExcelizeGenerator) {
+	for _, link := range l.links {
+		sheet, cell, ok := l.location(link.target, generator)
+
+		if !ok {
+			log.Warnf("Link target %q of cell %s!%s not found. Cell is not linked", link.target,
+				link.cell.Sheet, cellName(link.cell.Row, link.cell.Col))
+			continue
+		}
+
+		generator.SelectSheet(link.cell.Sheet)
+		generator.CurrentRow, generator.CurrentCol = link.cell.Row, link.cell.Col
+		generator.SetCellLocationLink(sheet, cell)
+	}
+}
+
+// location Returns sheet and cell (or range) of link target: "Sheet!A1", "'My sheet'!A1:B2", id of table
+// or cell, or sheet name (linked to its first cell)
+func (l *workbookLinks) location(target string, generator *generator.ExcelizeGenerator) (string, string, bool) {
+	if separator := strings.LastIndex(target, "!"); separator >= 0 {
+		sheet := target[:separator]
+		cell := strings.ToUpper(strings.ReplaceAll(target[separator+1:], "$", ""))
+
+		if len(sheet) > 1 && strings.HasPrefix(sheet, "'") && strings.HasSuffix(sheet, "'") {
+			sheet = strings.ReplaceAll(sheet[1:len(sheet)-1], "''", "'")
+		}
+
+		for _, name := range strings.SplitN(cell, ":", 2) {
+			if _, _, err := excelize.CellNameToCoordinates(name); err != nil {
+				return "", "", false
+			}
+		}
+
+		return sheet, cell, generator.HasSheet(sheet)
+	}
+
+	if target, ok := l.targets[target]; ok {
+		return target.Sheet, cellName(target.Row, target.Col), true
+	}
+
+	return target, "A1", generator.HasSheet(target)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCellLinks(t *testing.T) {
+	workbook := generateWorkbook(t, `
+		<table data-name="Report"><tr>
+			<td><a href="https://example.com/a?b=c">site</a></td>
+			<td><a href="mailto:info@example.com">mail</a> <a href="https://example.com">second</a></td>
+			<td><a href="#Other!b2">other</a></td>
+			<td><a href="#'My sheet'!$A$1:B2">range</a></td>
+			<td><a href="#totals">totals</a></td>
+			<td><a href="#Other">sheet</a></td>
+			<td><a href="#Missing!A1">missing</a></td>
+			<td><a href="#">empty</a></td>
+			<td><a href="javascript:void(0)">script</a></td>
+			<td><a href="#Other!ZZZZ1">bad cell</a></td>
+		</tr></table>
+		<table data-name="Other"><tr><td>x</td></tr><tr><td>y</td><td id="totals">total</td></tr></table>
+		<table data-name="My sheet"><tr><td>z</td></tr></table>`)
+
+	tests := []struct {
+		cell   string
+		linked bool
+		target string
+	}{
+		{"A1", true, "https://example.com/a?b=c"},
+		{"B1", true, "mailto:info@example.com"}, // the first link of cell
+		{"C1", true, "'Other'!B2"},
+		{"D1", true, "'My sheet'!A1:B2"},
+		{"E1", true, "'Other'!B2"}, // id of cell
+		{"F1", true, "'Other'!A1"}, // sheet name
+		{"G1", false, ""},
+		{"H1", false, ""},
+		{"I1", false, ""},
+		{"J1", false, ""},
+	}
+
+	for _, test := range tests {
+		linked, target, err := workbook.GetCellHyperLink("Report", test.cell)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if linked != test.linked || target != test.target {
+			t.Errorf("%s link is %v %q, want %v %q", test.cell, linked, target, test.linked, test.target)
+		}
+	}
+}
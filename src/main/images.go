@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/icewind666/html-to-excel-renderer/src/css"
+	"github.com/icewind666/html-to-excel-renderer/src/generator"
+	"github.com/icewind666/html-to-excel-renderer/src/images"
+	"github.com/jbowtie/gokogiri/xml"
+	"image"
+	"strings"
+)
+
+// SrcAttrName Image source attribute name: file path, url or data uri
+const SrcAttrName = "src"
+
+// AltAttrName Image alternative text attribute name. Written to cell when image can't be loaded
+const AltAttrName = "alt"
+
+// imageExtensions Extensions of picture formats supported by excel by image format name
+var imageExtensions = map[string]string{"png": ".png", "jpeg": ".jpg", "gif": ".gif"}
+
+// addImageToCell Inserts image to current cell taking cols x rows cells. Image is sized by width and height
+// styles or attributes (styles override attributes) in pixels or percents of cell range. When only one of them
+// is set the other keeps aspect ratio, image without size is shrunk to fit cell range.
+// Own are declarations of image, fontPixels is font size of cell for em lengths
+func addImageToCell(img xml.Node, own []css.Declaration, fontPixels float64, cols int, rows int,
+	resolver *images.Resolver, generator *generator.ExcelizeGenerator) error {
+	src := strings.TrimSpace(img.Attr(SrcAttrName))
+	picture, err := resolver.Load(src)
+
+	if err != nil {
+		return fmt.Errorf("cant load image %s: %v", images.ShortSource(src), err)
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(picture))
+
+	if err != nil {
+		return fmt.Errorf("cant decode image %s: %v", images.ShortSource(src), err)
+	}
+
+	extension, ok := imageExtensions[format]
+
+	if !ok || config.Width <= 0 || config.Height <= 0 {
+		return fmt.Errorf("unsupported image %s format %s", images.ShortSource(src), format)
+	}
+
+	cellWidth, cellHeight := generator.CellRangePixels(cols, rows)
+	width, hasWidth := imageLength(img, own, WidthStyleAttr, fontPixels, cellWidth)
+	height, hasHeight := imageLength(img, own, HeightStyleAttr, fontPixels, cellHeight)
+	naturalWidth, naturalHeight := float64(config.Width), float64(config.Height)
+
+	switch {
+	case hasWidth && !hasHeight:
+		height = naturalHeight * width / naturalWidth
+	case hasHeight && !hasWidth:
+		width = naturalWidth * height / naturalHeight
+	case !hasWidth && !hasHeight:
+		return generator.AddCellPicture(picture, extension, 1, 1, true)
+	}
+
+	return generator.AddCellPicture(picture, extension, width/naturalWidth, height/naturalHeight, false)
+}
+
+// imageLength Returns width or height of image in pixels set by style or attribute. Percents are relative
+// to cell range size
+func imageLength(img xml.Node, own []css.Declaration, property string, fontPixels float64,
+	cellSize float64) (float64, bool) {
+	value := img.Attr(property)
+
+	if declaration, ok := css.LastDeclaration(own, property); ok {
+		value = declaration.Value
+	}
+
+	if strings.TrimSpace(value) == "" {
+		return 0, false
+	}
+
+	pixels, ok := lengthPixels(property, value, fontPixels, cellSize)
+	return pixels, ok && pixels > 0
+}
@@ -2,7 +2,12 @@ package main
 
 import (
 	"fmt"
+	"github.com/icewind666/html-to-excel-renderer/src/css"
 	"github.com/icewind666/html-to-excel-renderer/src/generator"
+	"github.com/icewind666/html-to-excel-renderer/src/helpers"
+	"github.com/icewind666/html-to-excel-renderer/src/images"
+	"github.com/icewind666/html-to-excel-renderer/src/jshelpers"
+	"github.com/icewind666/html-to-excel-renderer/src/render"
 	"github.com/icewind666/html-to-excel-renderer/src/types"
 	"github.com/jbowtie/gokogiri"
 	"github.com/jbowtie/gokogiri/xml"
@@ -10,15 +15,17 @@ import (
 	"github.com/jessevdk/go-flags"
 	log "github.com/sirupsen/logrus"
 	_ "image"
+	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
 	"io/ioutil"
+	"math"
 	"os"
-	"os/exec"
 	"runtime"
 	"strconv"
 	"strings"
 	"time"
+	_ "time/tzdata" // time zones of localized helpers on systems without tz database
 )
 
 
@@ -29,24 +36,40 @@ var (
 )
 
 // XpathTable Search strings for html tags
-var XpathTable = xpath.Compile(".//table")
-var XpathThead = xpath.Compile(".//thead/tr")
-var XpathTh = xpath.Compile(".//th")
+var XpathTable = xpath.Compile(".//table[not(ancestor::table)]")
 var XpathTr = xpath.Compile("./tr")
-var XpathTd = xpath.Compile(".//td")
+var XpathRowCells = xpath.Compile("./th|./td")
 var XpathImg = xpath.Compile(".//img")
+var XpathStyle = xpath.Compile("//style")
+var XpathBase = xpath.Compile("//base[@href]")
 
 var opts struct {
 	Version bool `long:"version" description:"Show current version"`
-	UseHandleBars bool `long:"handlebars" description:"Use Handlebars template engine"`
-	Output string `long:"output" description:"Output xslx filepath"`
-	TemplateFile string `long:"template" description:"A Handlebars template file"`
-	DataFile string `long:"data" description:"A json data file. Used with handlebars rendering"`
-	HtmlFile string `long:"html" description:"Html rendered source file"`
+	UseHandleBars bool `long:"handlebars" description:"Use Handlebars template engine. Same as --engine=handlebars"`
+	Engine string `long:"engine" choice:"handlebars" choice:"gotemplate" choice:"none" description:"Template engine. Default is handlebars with --handlebars, none otherwise"`
+	Output string `long:"output" description:"Output xslx filepath. - writes to stdout"`
+	TemplateFile string `long:"template" description:"A template file (Handlebars or Go text/template)"`
+	DataFile string `long:"data" description:"A data file (json, yaml, csv or ndjson). Used with template rendering. - reads from stdin"`
+	DataFormat string `long:"data-format" choice:"auto" choice:"json" choice:"yaml" choice:"csv" choice:"ndjson" description:"Data file format. Default is auto (by file extension and content)"`
+	CsvDelimiter string `long:"csv-delimiter" description:"Csv data file delimiter. Default is comma, tab for .tsv files"`
+	CsvTypes bool `long:"csv-types" description:"Convert numbers and booleans in csv data file from strings, empty values to null"`
+	CssFile string `long:"css" description:"Css stylesheet file applied to html before <style> blocks of html"`
+	BaseDir string `long:"base-dir" description:"Directory of relative image paths. <base href> of html overrides it. Default is working directory"`
+	ImageMaxSize int64 `long:"image-max-size" description:"Max size of image file, data uri or downloaded image in bytes. Default is 10485760 (10 MB)"`
+	ImageTimeout time.Duration `long:"image-timeout" description:"Max time of downloading one http(s) image. Default is 10s"`
+	ImageCacheDir string `long:"image-cache" description:"Directory of downloaded images cache. Images are downloaded on every run without it"`
+	ImageCacheTTL time.Duration `long:"image-cache-ttl" description:"Max age of cached images. Default is 24h"`
+	HtmlFile string `long:"html" description:"Html rendered source file. - reads from stdin"`
+	NestedTables string `long:"nested-tables" choice:"grid" choice:"sheet" description:"Tables nested in cells are laid out inside the cell (grid) or written to their own sheets linked from the cell (sheet). Default is grid"`
 	BatchSize int `long:"batch-size" description:"Max rows for one iteration. Smaller size leads to smaller amount of memory used"`
 	PxWidthToExcel float64 `long:"px-width" description:"Multiplier used to map pixels in html to width in excel"`
 	PxHeightToExcel float64 `long:"px-height" description:"Multiplier used to map pixels in html to height in excel"`
 	HelpersPath string `long:"helpers" description:"Path to helpers folder. Used with handlebars rendering"`
+	Locale string `long:"locale" description:"Locale of localized helpers (localeDate, localeNumber, plural...): en, ru, uk, de, fr. Default is en"`
+	BuiltinHelpers string `long:"builtin-helpers" description:"Comma separated names of built-in helpers used with handlebars rendering. Default is all"`
+	PartialsPath string `long:"partials" description:"Path to partials folder (*.hbs files, registered by file name). Used with handlebars rendering"`
+	HelperTimeout time.Duration `long:"helper-timeout" description:"Max execution time of one javascript helper call. Default is 5s"`
+	Validate bool `long:"validate" description:"Check handlebars template against helpers, partials and data file instead of rendering. Exits with code 1 on problems"`
 	DebugMode bool `long:"debug" description:"Enable debug mode. Default is false"`
 	LogLevel string `long:"log-level" description:"Log level(info, warn, debug...). Default is info"`
 }
@@ -60,6 +83,10 @@ func main() {
 		log.WithError(err).Error("Can't parse command line arguments")
 	}
 
+	if opts.Output == types.StdioFilename || opts.Validate {
+		log.SetOutput(os.Stderr) // stdout is taken by workbook or validation report
+	}
+
 	showVersion := opts.Version
 
 	if showVersion {
@@ -69,7 +96,7 @@ func main() {
 
 	log.Infof("html-to-excel-renderer v%s, built at %s by %s", version, date, builtBy)
 
-	useHandlebars := opts.UseHandleBars
+	engineName := opts.Engine
 	template := opts.TemplateFile
 	htmlFile := opts.HtmlFile
 	data := opts.DataFile
@@ -85,20 +112,50 @@ func main() {
 		opts.PxHeightToExcel = 0.10 // default
 	}
 
-	if runtime.GOOS == "windows" && useHandlebars {
-		log.Fatalf("Current version does not support using Handlebars on Windows systems! Sorry! Will be fixed in next version")
+	if opts.Validate {
+		os.Exit(validateTemplate(template, data))
 	}
 
-	if useHandlebars && opts.HelpersPath == "" {
-		log.Error("Using Handlebars without setting helpers path will likely cause errors! Hope your template does not contain helpers :)")
-	} else {
-		opts.HelpersPath += "/*.js"
+	if output == "" {
+		log.Fatalln("Output file is not specified(--output)")
+	}
+
+	if countStdinInputs(template, htmlFile, data) > 1 {
+		log.Fatalln("Only one of --template, --html and --data can be read from stdin(-)")
+	}
+
+	if engineName == "" {
+		engineName = render.EngineNone
+
+		if opts.UseHandleBars {
+			engineName = render.EngineHandlebars
+		}
+	}
+
+	if opts.HelperTimeout <= 0 {
+		opts.HelperTimeout = jshelpers.DefaultTimeout
 	}
 
 	if batchSize <= 0 {
 		batchSize = 10_000_000
 	}
 
+	if opts.NestedTables == "" {
+		opts.NestedTables = NestedTablesGrid
+	}
+
+	if opts.ImageMaxSize <= 0 {
+		opts.ImageMaxSize = 10 << 20
+	}
+
+	if opts.ImageTimeout <= 0 {
+		opts.ImageTimeout = 10 * time.Second
+	}
+
+	if opts.ImageCacheTTL <= 0 {
+		opts.ImageCacheTTL = 24 * time.Hour
+	}
+
 	logLevel,err := log.ParseLevel(opts.LogLevel)
 
 	if err != nil {
@@ -115,12 +172,15 @@ func main() {
 	renderedHtml := ""
 	defer timeTrack(time.Now(), "main")
 
-	if useHandlebars {
-		renderedHtml = applyHbsRendering(template, data, opts.HelpersPath)
-		log.Infoln("Rendering Handlebars.js template to html is done")
-	} else {
+	if engineName == render.EngineNone {
+		if htmlFile == "" {
+			htmlFile = template
+		}
 		renderedHtml = ReadHtmlFile(htmlFile)
 		log.Infoln("Reading html is done")
+	} else {
+		renderedHtml = applyTemplateRendering(newEngine(engineName), template, data)
+		log.Infof("Rendering %s template to html is done", engineName)
 	}
 
 	generateXlsxFile(renderedHtml, output, batchSize)
@@ -135,31 +195,53 @@ func NewHtmlStyle() *types.HtmlStyle {
 		WordWrap:          false,
 		Width:             0,
 		Height:            0,
-		BorderInheritance: false,
-		BorderStyle:       false,
 		FontSize:          0,
 		IsBold:            false,
 		Colspan:           0,
 		VerticalAlign:     "",
 		CellValueType: StringValueType,
 		BackgroundColor:   "",
+		Color:             "",
+		FontFamily:        "",
+		IsItalic:          false,
+		Underline:         "",
+		IsStrike:          false,
+		Borders:           [4]types.Border{noBorder, noBorder, noBorder, noBorder},
 	}
 }
 
-// ReadHtmlFile Read and return file contents as string
+// ReadHtmlFile Read and return file contents as string. "-" reads stdin
 func ReadHtmlFile(htmlFilename string) string {
-	byteValue, _ := ioutil.ReadFile(htmlFilename)
 	if htmlFilename == "" {
 		log.Fatalln("Html file is not specified(--html)")
 	}
 
-	if byteValue == nil {
+	byteValue, err := render.ReadInput(htmlFilename)
+
+	if err != nil {
+		log.WithError(err).Fatalf("Can't read html file %s", htmlFilename)
+	}
+
+	if len(byteValue) == 0 {
 		log.Fatalf("Html file is empty? %s", htmlFilename)
 	}
 
 	return string(byteValue)
 }
 
+// countStdinInputs Returns number of input files set to stdin
+func countStdinInputs(filenames ...string) int {
+	count := 0
+
+	for _, filename := range filenames {
+		if filename == types.StdioFilename {
+			count++
+		}
+	}
+
+	return count
+}
+
 func NewExcelizeGenerator() *generator.ExcelizeGenerator {
 	return &generator.ExcelizeGenerator{
 		OpenedFile:   nil,
@@ -183,6 +265,12 @@ func generateXlsxFile(html string, outputFilename string, batchSize int) string
 	tables, _ := doc.Root().Search(XpathTable)
 	defer doc.Free()
 
+	document := &htmlDocument{
+		matcher: css.NewMatcher(loadStylesheet(doc.Root())),
+		links:   newWorkbookLinks(),
+		images:  newImageResolver(doc.Root()),
+	}
+
 	// creating excel excelizeGenerator
 	excelizeGenerator := NewExcelizeGenerator()
 	excelFilename := fmt.Sprintf("%s", outputFilename)
@@ -193,298 +281,552 @@ func generateXlsxFile(html string, outputFilename string, batchSize int) string
 
 	totalRows := 0
 	currentSheetIndex := 0
+	sheetTables := make([]sheetTable, 0, len(tables))
 
-	// Main cycle through all tables in file
 	for i, table := range tables {
-		// Create new sheet for each table. Name it with data-name from html attribute
-		sheetName := table.Attr("data-name")
+		// Create new sheet for each table. Name it with data-sheet (shared by tables) or data-name from html attribute
+		sheetName := table.Attr(SheetAttrName)
+
+		if sheetName == "" {
+			sheetName = table.Attr("data-name")
+		}
 
 		if sheetName == "" {
 			sheetName = fmt.Sprintf("DataSheet %d", i)
 			log.Infof("Warning! No data-name in for table found. Used %s as sheet name\n", sheetName)
 		}
 
-		if currentSheetIndex == 0 {
+		sheetTables = append(sheetTables, sheetTable{Node: table, Name: sheetName})
+	}
+
+	placements := make(map[string]*sheetPlacement) // tables placed on sheet by sheet name
+
+	// Main cycle through all tables in file. Nested tables written to their own sheets are added to the end
+	for i := 0; i < len(sheetTables); i++ {
+		sheetName := sheetTables[i].Name
+		placement, exists := placements[sheetName]
+
+		if exists {
+			excelizeGenerator.SelectSheet(sheetName)
+		} else if currentSheetIndex == 0 {
 			excelizeGenerator.SetSheetName("Sheet1", sheetName)
 		} else {
 			excelizeGenerator.AddSheet(sheetName)
 		}
 
-		excelizeGenerator.CurrentCol = 1
-		excelizeGenerator.CurrentRow = 0
+		if !exists {
+			placement = &sheetPlacement{}
+			placements[sheetName] = placement
+			currentSheetIndex += 1
+		}
 
-		// Get thead for table and create header in xlsx
-		theadTrs, _ := table.Search(XpathThead)
-		processHtmlTheadTag(theadTrs, excelizeGenerator)
+		layout := layoutTable(sheetTables[i].Node, opts.NestedTables)
+		row, col := placement.Place(sheetTables[i].Node, layout.TotalsStart(), layout.Width())
+		rows, nestedSheets := writeTableLayout(layout, excelizeGenerator, document, nil, row, col, batchSize, placement)
 
-		// Get all rows in html table
-		rows, _ := table.Search(XpathTr)
-		rowsProceeded := 0
-		packSize := batchSize
+		sheetTables = append(sheetTables, nestedSheets...)
+		totalRows += rows // stored only for log output
 
-		for rowsProceeded < len(rows) {
-			processTableRows(rows, excelizeGenerator, rowsProceeded, packSize)
-			rowsProceeded += packSize
+		if !hasSheetTable(sheetTables[i+1:], sheetName) {
+			// all tables of the sheet are placed, totals go under them
+			rows, nestedSheets = writeSheetTotals(placement, excelizeGenerator, document, batchSize)
+			sheetTables = append(sheetTables, nestedSheets...)
+			totalRows += rows
 		}
-
-		totalRows += len(rows) // stored only for log output
-		rows = nil // just for sure. prevent memory leak which was found during tests in 3rd party lib
-		currentSheetIndex += 1
 	}
 
+	document.links.Resolve(excelizeGenerator) // all link targets are written
 	excelizeGenerator.Save(excelizeGenerator.Filename)
 
 	log.Infof("Total rows done: %d", totalRows)
 	return excelFilename
 }
 
-// processTableRows Process all html table rows. Starts with <th> table headers then goes over <tr> and <td> inside them.
-func processTableRows(rows []xml.Node, generator *generator.ExcelizeGenerator, offset int, rowsNumber int) {
-	defer timeTrack(time.Now(), "processTableRows")
-	if offset >= len(rows) {
-		return // offset cant be greater than number of rows
+// loadStylesheet Returns stylesheet of --css file and <style> blocks of html document, in this order
+func loadStylesheet(root xml.Node) *css.Stylesheet {
+	stylesheet := &css.Stylesheet{}
+
+	if opts.CssFile != "" {
+		source, err := ioutil.ReadFile(opts.CssFile)
+
+		if err != nil {
+			log.WithError(err).Fatalf("Can't read css file %s", opts.CssFile)
+		}
+
+		for _, err := range stylesheet.Add(string(source)) {
+			log.WithError(err).Warnf("Css rule skipped in %s", opts.CssFile)
+		}
 	}
 
-	if len(rows) < rowsNumber {
-		rowsNumber = len(rows) // when less than one page
+	styles, _ := root.Search(XpathStyle)
+
+	for _, style := range styles {
+		for _, err := range stylesheet.Add(style.Content()) {
+			log.WithError(err).Warn("Css rule skipped in <style>")
+		}
 	}
 
-	for i := offset; i <= (offset + rowsNumber - 1); i++ {
-		if i >= len(rows) {
-			break // we are done here
+	return stylesheet
+}
+
+// newImageResolver Returns resolver of images relative to --base-dir or <base href> of html document.
+// Http(s) images are downloaded with --image-timeout and cached in --image-cache directory
+func newImageResolver(root xml.Node) *images.Resolver {
+	resolver := images.NewResolver(opts.BaseDir, opts.ImageMaxSize)
+	var fetcher images.Fetcher = images.NewHTTPFetcher(opts.ImageTimeout, opts.ImageMaxSize)
+
+	if opts.ImageCacheDir != "" {
+		fetcher = images.NewCachingFetcher(fetcher, opts.ImageCacheDir, opts.ImageCacheTTL)
+	}
+
+	resolver.Fetchers["http"] = fetcher
+	resolver.Fetchers["https"] = fetcher
+	bases, _ := root.Search(XpathBase)
+
+	if len(bases) > 0 {
+		if err := resolver.SetBase(strings.TrimSpace(bases[0].Attr(HrefAttrName))); err != nil {
+			log.WithError(err).Warn("<base> ignored")
 		}
+	}
 
-		tr := rows[i]
-		generator.AddRow()
+	return resolver
+}
 
-		theadTrs, _ := tr.Search(XpathTh)
-		generator.CurrentCol = 1
+// htmlDocument Document wide state of tables writing: stylesheet, link targets and images
+type htmlDocument struct {
+	matcher *css.Matcher
+	links   *workbookLinks
+	images  *images.Resolver
+}
 
-		// <th>
-		for _, theadTh := range theadTrs {
-			thStyle := theadTh.Attribute(StyleAttrName)
-			cellValue := theadTh.Content()
+// sheetTable Html table written to its own sheet
+type sheetTable struct {
+	Node xml.Node
+	Name string
+}
 
-			if thStyle != nil {
-				style := ExtractStyles(thStyle)
-				thColspan := theadTh.Attribute(ColspanAttrName)
+// writeTableLayout Writes table laid out on grid starting at given excel row and column, section by section
+// in batches of batchSize rows. Table inherits given declarations (of cell it is nested in).
+// Table and its cells with id are registered as link targets. Totals sections of tables placed on sheet
+// (placement is nil for nested tables) are deferred to the bottom of the sheet.
+// Returns number of rows written and nested tables to be written to their own sheets
+func writeTableLayout(layout *tableLayout, generator *generator.ExcelizeGenerator, document *htmlDocument,
+	inherited []css.Declaration, originRow int, originCol int, batchSize int, placement *sheetPlacement) (int, []sheetTable) {
+	totalRows := 0
+	var nestedSheets []sheetTable
+	var totals []*layoutSection
+	styles := newTableStyles(layout.Table, document.matcher, inherited)
+	document.links.AddTarget(layout.Table, generator.CurrentSheet, originRow, originCol)
+
+	for _, section := range layout.Sections {
+		if section.Section.Totals && placement != nil {
+			totals = append(totals, section)
+			continue
+		}
 
-				if thColspan != nil {
-					style.Colspan,_ = strconv.Atoi(thColspan.Value())
-					generator.ApplyBordersRange(style)
-				}
+		nestedSheets = append(nestedSheets, writeSection(section, layout, generator, styles, document, originRow, originCol,
+			batchSize)...)
+		totalRows += len(section.Rows)
+	}
 
-				generator.ApplyColumnStyle(style)
-				generator.ApplyCellStyle(style)
-			}
+	if len(totals) > 0 {
+		placement.DeferTotals(tableTotals{Layout: layout, Styles: styles, Sections: totals, Col: originCol})
+	}
 
-			// <img>
-			// NOTE: is it valid to have img in th?)
-			imgs, _ := theadTh.Search(XpathImg)
+	return totalRows, nestedSheets
+}
 
-			if len(imgs) > 0 {
-				for _, img := range imgs {
-					addImageToCell(img, generator)
-				}
-			} else {
-				if cellValue != "" {
+// writeSheetTotals Writes deferred totals sections of tables under all tables of the sheet.
+// Returns number of rows written and nested tables to be written to their own sheets
+func writeSheetTotals(placement *sheetPlacement, generator *generator.ExcelizeGenerator, document *htmlDocument,
+	batchSize int) (int, []sheetTable) {
+	totalRows := 0
+	var nestedSheets []sheetTable
+	placed, rows := placement.PlaceTotals()
 
-					generator.SetCellValue(cellValue)
-				}
-			}
+	for i, totals := range placed {
+		originRow := rows[i] - totals.Layout.TotalsStart() // totals rows are relative to table
 
-			generator.CurrentCol += 1
+		for _, section := range totals.Sections {
+			nestedSheets = append(nestedSheets, writeSection(section, totals.Layout, generator, totals.Styles, document,
+				originRow, totals.Col, batchSize)...)
+			totalRows += len(section.Rows)
 		}
+	}
 
-		cells, _ := tr.Search(XpathTd)
-		generator.CurrentCol = 1
+	return totalRows, nestedSheets
+}
 
-		// table td cells
-		for _, td := range cells {
-			tdStyle := td.Attribute("style")
-			cellValue := td.Content()
-			cellValueType := StringValueType
+// writeSection Writes rows of table section in batches of batchSize rows.
+// Returns nested tables to be written to their own sheets
+func writeSection(section *layoutSection, layout *tableLayout, generator *generator.ExcelizeGenerator,
+	styles *tableStyles, document *htmlDocument, originRow int, originCol int, batchSize int) []sheetTable {
+	var nestedSheets []sheetTable
+	rowsProceeded := 0
+
+	for rowsProceeded < len(section.Rows) {
+		nested := processTableRows(section, layout, generator, styles, document, originRow, originCol, rowsProceeded,
+			batchSize)
+		nestedSheets = append(nestedSheets, nested...)
+		rowsProceeded += batchSize
+	}
 
-			if tdStyle != nil {
-				cellStyle := ExtractStyles(tdStyle)
-				tdColspan := td.Attribute(ColspanAttrName)
-				cellValueType = cellStyle.CellValueType
-				if tdColspan != nil {
-					cellStyle.Colspan,_ = strconv.Atoi(tdColspan.Value())
-					generator.ApplyBordersRange(cellStyle)
-				}
+	return nestedSheets
+}
 
-				generator.ApplyCellStyle(cellStyle)
-			}
+// hasSheetTable Checks tables include table placed on sheet
+func hasSheetTable(tables []sheetTable, sheetName string) bool {
+	for _, table := range tables {
+		if table.Name == sheetName {
+			return true
+		}
+	}
 
-			imgs, _ := td.Search(XpathImg)
+	return false
+}
 
-			if len(imgs) > 0 {
-				for _, img := range imgs {
-					addImageToCell(img, generator)
-				}
-			} else {
-				if cellValue != "" {
-					switch cellValueType {
-					case FloatValueType:
-						floatContent,err := strconv.ParseFloat(cellValue, 64)
-
-						if err != nil {
-							log.WithError(err).Error("Cant parse cell type")
-						}
-
-						generator.SetCellFloatValue(floatContent)
-					case BooleanValueType:
-						boolContent, err := strconv.ParseBool(cellValue)
-
-						if err != nil {
-							log.WithError(err).Error("Cant parse bool value from string")
-						}
-						generator.SetCellBoolValue(boolContent)
-					default:
-						generator.SetCellValue(cellValue)
-					}
-				}
-			}
+// processTableRows Process html table rows of section. Cells (<th> and <td>) are written at their excel ranges
+// of table layout with declarations inherited from table, column, section and row.
+// Returns nested tables to be written to their own sheets
+func processTableRows(section *layoutSection, layout *tableLayout, generator *generator.ExcelizeGenerator,
+	styles *tableStyles, document *htmlDocument, originRow int, originCol int, offset int, rowsNumber int) []sheetTable {
+	defer timeTrack(time.Now(), "processTableRows")
+	rows := section.Rows
+	sectionStyle := styles.Own(section.Section.Node)
+	var nestedSheets []sheetTable
+
+	if offset >= len(rows) {
+		return nil // offset cant be greater than number of rows
+	}
+
+	if len(rows) < rowsNumber {
+		rowsNumber = len(rows) // when less than one page
+	}
 
-			generator.CurrentCol += 1
+	for i := offset; i <= (offset + rowsNumber - 1); i++ {
+		if i >= len(rows) {
+			break // we are done here
 		}
 
-		trStyle := tr.Attribute("style")
+		row := rows[i]
+		trStyle := styles.Own(row.Node)
+
+		for _, cell := range row.Cells {
+			cellRow, cellCol, cellRows, cellCols := layout.CellRange(cell.gridCell)
+			generator.CurrentRow = originRow + cellRow
+			generator.CurrentCol = originCol + cellCol
+			declarations := styles.Cell(cell.Node, cell.Col, sectionStyle, trStyle)
+			nested := processTableCell(cell, cellRows, cellCols, section.Section, declarations, generator, styles, document)
+			nestedSheets = append(nestedSheets, nested...)
+		}
 
 		// Apply row style if present
-		if trStyle != nil {
-			styleExtracted := ExtractStyles(trStyle)
+		if len(trStyle) > 0 {
+			generator.CurrentRow = originRow + layout.RowStart(row.GridRow)
+			styleExtracted := ExtractStylesDeclarations(trStyle, styles.width)
 			generator.ApplyRowStyle(styleExtracted)
 		}
 	}
+
+	return nestedSheets
 }
 
-// addImageToCell Inserts image to current cell. Or its alternative text
-func addImageToCell(img xml.Node, generator *generator.ExcelizeGenerator) {
-	imgSrc := img.Attribute("src")
-	imgAlt := img.Attribute("alt")
+// processTableCell Sets value (or images) and style of <th> or <td> cell taking rows x cols excel cells
+// from current cell. Style is made of computed cell declarations (own and inherited), multi-line text is wrapped.
+// Header cells always get style, they also set column style. Spanned cells are merged. Nested tables
+// are written inside cell range and inherit cell declarations, or linked from cell when written
+// to their own sheets (returned). Text cells are linked to href of their link
+func processTableCell(cell layoutCell, rows int, cols int, section *tableSection, declarations []css.Declaration,
+	generator *generator.ExcelizeGenerator, styles *tableStyles, document *htmlDocument) []sheetTable {
+	node := cell.Node
+	document.links.AddTarget(node, generator.CurrentSheet, generator.CurrentRow, generator.CurrentCol)
+	style := ExtractStylesDeclarations(declarations, styles.width)
+	style.Colspan = cols
+	style.Rowspan = rows
+	isHeader := section.IsHeader()
+	var imgs []xml.Node
+	var runs richTextRuns
+	text := ""
+
+	if !cell.HasNested() {
+		imgs, _ = node.Search(XpathImg)
+
+		if len(imgs) == 0 {
+			text, runs = cellText(node, declarations, style, document.matcher)
+			style.WordWrap = style.WordWrap || strings.Contains(text, "\n") // line breaks are shown in wrapped cells only
+		}
+	}
 
-	// If file exist - set image to cell
-	if _, err := os.Stat(imgSrc.Value()); os.IsNotExist(err) {
-		if err != nil {
-			log.WithError(err).Errorln("Cant access image file")
+	if isHeader || len(declarations) > 0 || style.WordWrap {
+		if isHeader || node.Name() == "th" {
+			generator.ApplyColumnStyle(style)
+		}
+
+		if len(cell.Nested) > 0 {
+			generator.ApplyRangeStyle(style) // range of nested table cells is not merged
+		} else {
+			generator.ApplyCellStyle(style)
 		}
-		generator.SetCellValue(imgAlt.Value())
+	} else if len(cell.Nested) == 0 {
+		generator.MergeSpan(cols, rows)
+	}
+
+	if cell.HasNested() {
+		return processNestedTables(cell, declarations, generator, document)
 	}
 
-	currentCellCoords, errCoords := generator.GetCoords()
+	if len(imgs) > 0 {
+		var alts []string
+		fontPixels := fontSizePixels(declarations)
+
+		for _, img := range imgs {
+			if err := addImageToCell(img, styles.Own(img), fontPixels, cols, rows, document.images, generator); err != nil {
+				log.WithError(err).Warn("Image is replaced with its alternative text")
 
-	if errCoords != nil {
-		log.WithError(errCoords).Errorln(errCoords)
+				if alt := strings.TrimSpace(img.Attr(AltAttrName)); alt != "" {
+					alts = append(alts, alt)
+				}
+			}
+		}
+
+		setCellContent(strings.Join(alts, " "), StringValueType, generator)
+		return nil
 	}
 
-	errAdd := generator.OpenedFile.AddPicture(generator.CurrentSheet,
-		currentCellCoords,
-		imgSrc.Value(),
-		`{"autofit":true, "lock_aspect_ratio": true, "positioning": "oneCell"}`)
-	if errAdd != nil {
-		log.Printf(errAdd.Error())
+	if len(runs) > 0 && style.CellValueType == StringValueType {
+		generator.SetCellRichText(runs) // inline elements with their own fonts
+	} else {
+		setCellContent(text, style.CellValueType, generator)
 	}
+
+	document.links.SetCellLink(node, generator)
+	return nil
 }
 
+// processNestedTables Writes tables nested in cell one under another starting at current cell.
+// Nested tables inherit given declarations of the cell. Tables written to their own sheets are linked
+// from the cell, their sheets are created to reserve names
+func processNestedTables(cell layoutCell, inherited []css.Declaration, generator *generator.ExcelizeGenerator,
+	document *htmlDocument) []sheetTable {
+	row, col := generator.CurrentRow, generator.CurrentCol
+	var nestedSheets []sheetTable
+
+	for _, nested := range cell.Nested {
+		_, sheets := writeTableLayout(nested, generator, document, inherited, row, col, nested.Height(), nil) // nested table in one batch
+		nestedSheets = append(nestedSheets, sheets...)
+		row += nested.Height()
+	}
+
+	for _, table := range cell.NestedSheets {
+		sheetName := table.Attr("data-name")
+
+		if sheetName == "" {
+			sheetName = generator.CurrentSheet + " nested"
+		}
 
-// processHtmlTheadTag Process thead tag (thead->tr + thead->tr->th). Apply column styles. Apply cell styles
-func processHtmlTheadTag(theadTrs []xml.Node, generator *generator.ExcelizeGenerator) {
-	defer timeTrack(time.Now(), "processHtmlTheadTag")
+		sheetName = generator.ReserveSheet(sheetName)
+		generator.CurrentRow, generator.CurrentCol = row, col
+		generator.SetCellSheetLink(sheetName)
+		nestedSheets = append(nestedSheets, sheetTable{Node: table, Name: sheetName})
+		row++
+	}
 
-	for _, theadTr := range theadTrs {
-		generator.AddRow()
-		theadTrThs, _ := theadTr.Search(XpathTh) // search for <th>
-		colIndex := 1
+	return nestedSheets
+}
 
-		for _, theadTh := range theadTrThs { // for each <th> in <tr>
-			thStyle := theadTh.Attribute(StyleAttrName)
+// setCellContent Sets cell value converted to cell value type
+func setCellContent(content string, valueType types.ValueType, generator *generator.ExcelizeGenerator) {
+	if content == "" {
+		return
+	}
 
-			style := ExtractStyles(thStyle)
-			thColspan := theadTh.Attribute(ColspanAttrName)
+	switch valueType {
+	case FloatValueType:
+		floatContent, err := strconv.ParseFloat(content, 64)
 
-			if thColspan != nil {
-				style.Colspan, _ = strconv.Atoi(thColspan.Value())
-				generator.ApplyBordersRange(style)
-			}
+		if err != nil {
+			log.WithError(err).Error("Cant parse cell type")
+		}
 
+		generator.SetCellFloatValue(floatContent)
+	case BooleanValueType:
+		boolContent, err := strconv.ParseBool(content)
 
+		if err != nil {
+			log.WithError(err).Error("Cant parse bool value from string")
+		}
+		generator.SetCellBoolValue(boolContent)
+	default:
+		generator.SetCellValue(content)
+	}
+}
 
-			content := theadTh.Content()
+// loadHelpers Returns selected built-in helpers and javascript helpers from helpers path.
+// Javascript helpers override built-in helpers with the same name
+func loadHelpers(builtinNames string, helpersPath string) map[string]interface{} {
+	names, err := helpers.ParseNames(builtinNames)
 
-			if content != "" {
-				switch style.CellValueType {
-				case FloatValueType:
-					floatContent,err := strconv.ParseFloat(content, 64)
+	if err != nil {
+		log.WithError(err).Fatal("Can't select built-in helpers!")
+	}
 
-					if err != nil {
-						log.WithError(err).Error("Cant parse cell type")
-					}
+	result := helpers.Select(loadLocale(), names...)
 
-					generator.SetCellFloatValue(floatContent)
-				case BooleanValueType:
-					boolContent, err := strconv.ParseBool(content)
+	if helpersPath == "" {
+		return result
+	}
 
-					if err != nil {
-						log.WithError(err).Error("Cant parse bool value from string")
-					}
-					generator.SetCellBoolValue(boolContent)
-				default:
-					generator.SetCellValue(content)
-				}
-			}
+	jsRuntime := jshelpers.New()
+	jsRuntime.Timeout = opts.HelperTimeout
 
-			if style != nil {
-				log.Infoln("ApplyColumnStyle")
-				generator.ApplyColumnStyle(style)
-				generator.ApplyCellStyle(style)
-			}
+	if err := jsRuntime.LoadDir(helpersPath); err != nil {
+		log.WithError(err).Fatal("Can't load javascript helpers!")
+	}
 
-			colIndex++
+	for name, helper := range jsRuntime.Helpers() {
+		if _, exists := result[name]; exists {
+			log.Debugf("Javascript helper %s overrides built-in helper", name)
 		}
+		result[name] = helper
+	}
 
-		thStyle := theadTr.Attribute(StyleAttrName)
+	log.Infof("Loaded %d javascript helpers from %s", len(jsRuntime.Names()), helpersPath)
+	return result
+}
 
-		if thStyle != nil {
-			rowStyle := ExtractStyles(thStyle)
-			log.Infoln(thStyle)
-			thColspan := thStyle.Attribute(ColspanAttrName)
-			log.Infoln("colspan", thColspan)
+// loadLocale Returns locale of localized helpers set from command line options
+func loadLocale() *helpers.Locale {
+	locale, err := helpers.ParseLocale(opts.Locale)
 
-			if thColspan != nil {
-				log.Infoln("Applying row colspan")
-				rowStyle.Colspan, _ = strconv.Atoi(thColspan.Value())
-			}
-			if rowStyle != nil {
-				log.Infoln("Applying row style")
-				generator.ApplyRowStyle(rowStyle)
-			}
-		} else {
-			log.Infoln("No row style found")
+	if err != nil {
+		log.WithError(err).Fatal("Can't set locale!")
+	}
+
+	return locale
+}
+
+// loadPartials Returns handlebars partials from partials path
+func loadPartials(partialsPath string) map[string]string {
+	if partialsPath == "" {
+		return nil
+	}
+
+	partials, err := render.LoadPartials(partialsPath)
+
+	if err != nil {
+		log.WithError(err).Fatal("Can't load partials!")
+	}
+
+	log.Infof("Loaded %d partials from %s", len(partials), partialsPath)
+	return partials
+}
+
+// newEngine Creates template engine by name with helpers and functions set from command line options
+func newEngine(engineName string) render.Engine {
+	switch engineName {
+	case render.EngineHandlebars:
+		return &render.HandlebarsEngine{
+			Helpers:  loadHelpers(opts.BuiltinHelpers, opts.HelpersPath),
+			Partials: loadPartials(opts.PartialsPath),
 		}
+	case render.EngineGoTemplate:
+		return &render.GoTemplateEngine{Funcs: helpers.TemplateFuncs(loadLocale())}
 	}
+
+	return &render.NoneEngine{}
 }
 
-// applyHbsRendering Calls shell hbs-cli to process handlebars.js template
-func applyHbsRendering(templateFilename string, dataFilename string, helpersPath string) string {
-	defer timeTrack(time.Now(), "applyHbsRendering")
-	args := []string{"--helper", helpersPath, "--data", dataFilename, templateFilename, "--stdout"}
-	cmd := exec.Command("hbs", args...)
-	outStr, err := cmd.Output()
+// loadData Reads data file in format set from command line options
+func loadData(dataFilename string) interface{} {
+	dataOptions := render.DataOptions{
+		Format:   opts.DataFormat,
+		CsvTypes: opts.CsvTypes,
+	}
+
+	if opts.CsvDelimiter != "" {
+		dataOptions.CsvDelimiter = []rune(strings.ReplaceAll(opts.CsvDelimiter, `\t`, "\t"))[0]
+	}
+
+	data, err := render.LoadData(dataFilename, dataOptions)
 
 	if err != nil {
-		log.WithError(err).Fatal("Can't run hbs shell command!")
+		log.WithError(err).Fatal("Can't load data file!")
+	}
+
+	return data
+}
+
+// applyTemplateRendering Renders template with data file using given engine
+func applyTemplateRendering(engine render.Engine, templateFilename string, dataFilename string) string {
+	defer timeTrack(time.Now(), "applyTemplateRendering")
+	outStr, err := render.RenderFile(engine, templateFilename, loadData(dataFilename))
+
+	if err != nil {
+		log.WithError(err).Fatal("Can't render template!")
 	}
 
 	if opts.DebugMode {
-		err := ioutil.WriteFile("./debug.html", outStr, 0777)
+		err := ioutil.WriteFile("./debug.html", []byte(outStr), 0777)
 		if err != nil {
 			log.Warn("Can't write debug html file!")
 		}
 	}
 
-	return string(outStr)
+	return outStr
+}
+
+// validateTemplate Checks handlebars template against helpers, partials and data file (when given).
+// Prints report to stdout and returns process exit code: 1 when problems found
+func validateTemplate(templateFilename string, dataFilename string) int {
+	if templateFilename == "" {
+		log.Fatalln("Template file is not specified(--template)")
+	}
+
+	source, err := render.ReadInput(templateFilename)
+
+	if err != nil {
+		log.WithError(err).Fatalf("Can't read template file %s", templateFilename)
+	}
+
+	var data interface{}
+
+	if dataFilename != "" {
+		data = loadData(dataFilename)
+	}
+
+	engine := &render.HandlebarsEngine{
+		Helpers:  loadHelpers(opts.BuiltinHelpers, opts.HelpersPath),
+		Partials: loadPartials(opts.PartialsPath),
+	}
+
+	report, err := engine.Validate(templateFilename, string(source), data)
+
+	if err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+
+	fmt.Println("Data paths:")
+	for _, path := range report.Paths {
+		fmt.Printf("  %s\n", path)
+	}
+
+	fmt.Println("Helpers:")
+	for _, helper := range report.Helpers {
+		fmt.Printf("  %s\n", helper)
+	}
+
+	if !report.HasProblems() {
+		fmt.Println("No problems found")
+		return 0
+	}
+
+	fmt.Printf("Problems (%d):\n", len(report.Problems))
+	for _, problem := range report.Problems {
+		fmt.Printf("  %s\n", problem)
+	}
+
+	return 1
 }
 
 
@@ -494,110 +836,221 @@ func ExtractStyles(node *xml.AttributeNode) *types.HtmlStyle {
 		return NewHtmlStyle()
 	}
 
-	styleStr := node.Content()
-	entries := strings.Split(styleStr, ";")
+	return ExtractStylesString(node.Content())
+}
+
+// ExtractStylesString Returns parsed style struct of style attribute value. Later declarations override earlier ones
+func ExtractStylesString(styleStr string) *types.HtmlStyle {
+	return ExtractStylesDeclarations(css.ParseDeclarations(styleStr), 0)
+}
+
+// ExtractStylesDeclarations Returns style struct of css declarations. Later declarations override earlier ones.
+// Percentage widths are relative to tableWidth (css pixels, 0 when unknown), em lengths to font size
+func ExtractStylesDeclarations(declarations []css.Declaration, tableWidth float64) *types.HtmlStyle {
 	resultStyle := NewHtmlStyle()
+	fontPixels := fontSizePixels(declarations)
+
+	for _, declaration := range declarations {
+		value := declaration.Value
+		attr := declaration.Property
 
-	for _, e := range entries {
-		if e != "" {
-			parts := strings.Split(e, ":")
+		switch attr {
+		case ColspanAttrName:
+			resultStyle.Colspan, _ = strconv.Atoi(value)
 
-			if len(parts) < 2 {
-				continue
+		case TextAlignStyleAttr:
+			resultStyle.TextAlign = value
+
+		case WordWrapStyleAttr:
+			resultStyle.WordWrap = value == BreakWordWrapStyleAttrValue
+
+		case WidthStyleAttr:
+			if width, ok := lengthPixels(attr, value, fontPixels, tableWidth); ok {
+				resultStyle.Width = width * opts.PxWidthToExcel
 			}
 
-			value := strings.Trim(parts[1], " ")
-			attr := strings.Trim(parts[0], " ")
+		case MinWidthStyleAttr, MaxWidthStyleAttr:
+			if width, ok := lengthPixels(attr, value, fontPixels, tableWidth); ok && resultStyle.Width <= 0 {
+				resultStyle.Width = width * opts.PxWidthToExcel
+			}
 
-			switch attr {
-			case ColspanAttrName:
-				resultStyle.Colspan, _ = strconv.Atoi(value)
+		case HeightStyleAttr:
+			if height, ok := lengthPixels(attr, value, fontPixels, 0); ok {
+				resultStyle.Height = height * opts.PxHeightToExcel
+			}
 
-			case TextAlignStyleAttr:
-				resultStyle.TextAlign = value
+		case MinHeightStyleAttr, MaxHeightStyleAttr:
+			if height, ok := lengthPixels(attr, value, fontPixels, 0); ok && resultStyle.Height <= 0 {
+				resultStyle.Height = height * opts.PxHeightToExcel
+			}
+		case BorderInheritanceStyleAttr: // legacy alias of border-style
+			applyBorderDeclaration(resultStyle, BorderStyleAttr, value, fontPixels)
 
-			case WordWrapStyleAttr:
-				resultStyle.WordWrap = value == BreakWordWrapStyleAttrValue
+		case FontSizeStyleAttr:
+			resultStyle.FontSize = fontPoints(fontPixels)
 
-			case WidthStyleAttr:
-				widthEntry := strings.Trim(value, " px")
-				widthInt, _ := strconv.Atoi(widthEntry)
-				translatedWidth := float64(widthInt) * opts.PxWidthToExcel
-				resultStyle.Width = translatedWidth
+		case FontWeightStyleAttr:
+			if bold, err := css.IsBold(value); err == nil {
+				resultStyle.IsBold = bold
+			} else {
+				log.WithError(err).Warn("Font weight ignored")
+			}
 
-			case MinWidthStyleAttr:
-				if resultStyle.Width <= 0 {
-					widthEntry := strings.Trim(value, " px")
-					widthInt, _ := strconv.Atoi(widthEntry)
-					translatedWidth := float64(widthInt) * opts.PxWidthToExcel
-					resultStyle.Width = translatedWidth
-				}
-			case MaxWidthStyleAttr:
-				if resultStyle.Width <= 0 {
-					widthEntry := strings.Trim(value, " px")
-					widthInt, _ := strconv.Atoi(widthEntry)
-					translatedWidth := float64(widthInt) * opts.PxWidthToExcel
-					resultStyle.Width = translatedWidth
-				}
+		case FontStyleAttr:
+			fontStyle := strings.ToLower(value)
+			resultStyle.IsItalic = fontStyle == "italic" || fontStyle == "oblique"
 
-			case HeightStyleAttr:
-				heightEntry := strings.Trim(value, " px")
-				heightInt, _ := strconv.Atoi(heightEntry)
-				translatedHeight := float64(heightInt) * opts.PxHeightToExcel
-				resultStyle.Height = translatedHeight
-
-			case MinHeightStyleAttr:
-				if resultStyle.Height <= 0 {
-					heightEntry := strings.Trim(value, " px")
-					heightInt, _ := strconv.Atoi(heightEntry)
-					translatedHeight := float64(heightInt) * opts.PxHeightToExcel
-					resultStyle.Height = translatedHeight
-				}
-			case MaxHeightStyleAttr:
-				if resultStyle.Height <= 0 {
-					heightEntry := strings.Trim(value, " px")
-					heightInt, _ := strconv.Atoi(heightEntry)
-					translatedHeight := float64(heightInt) * opts.PxHeightToExcel
-					resultStyle.Height = translatedHeight
-				}
-			case BorderStyleAttr:
-				resultStyle.BorderStyle = value == BorderStyleAttrValue
+		case FontFamilyStyleAttr:
+			resultStyle.FontFamily = css.FontFamily(value)
 
-			case BorderInheritanceStyleAttr:
-				resultStyle.BorderStyle = value == BorderInheritanceStyleAttrValue
+		case FontStyleShorthandAttr:
+			font, err := css.ParseFont(value)
 
-			case FontSizeStyleAttr:
-				widthEntry := strings.Trim(value, " px")
-				sz,_ := strconv.Atoi(widthEntry)
-				resultStyle.FontSize = float64(sz)
+			if err != nil {
+				log.WithError(err).Warn("Font ignored")
+				break
+			}
 
-			case FontWeightStyleAttr:
-				resultStyle.IsBold = strings.Contains(value, "bold")
+			resultStyle.IsItalic = font.Style != "normal"
+			resultStyle.IsBold, _ = css.IsBold(font.Weight)
+			resultStyle.FontSize = fontPoints(fontPixels)
+			resultStyle.FontFamily = css.FontFamily(font.Family)
 
-			case TextVerticalAlignStyleAttr:
-				if value == "middle" {
-					value = "center" // excelize lib dont understand middle :) center works fine
-				}
-				resultStyle.VerticalAlign = value
-			case ValueTypeAttrName:
-				cellType := types.ValueType(value)
-				if cellType == "float" {
-					resultStyle.CellValueType = cellType
-				}
-				if cellType == "bool" {
-					resultStyle.CellValueType = cellType
-				}
-			case BackgroundColorAttrName:
-				resultStyle.BackgroundColor = value
+		case TextDecorationStyleAttr, TextDecorationLineStyleAttr:
+			applyTextDecoration(resultStyle, value)
+
+		case TextVerticalAlignStyleAttr:
+			if value == "middle" {
+				value = "center" // excelize lib dont understand middle :) center works fine
+			}
+			resultStyle.VerticalAlign = value
+		case ValueTypeAttrName:
+			cellType := types.ValueType(value)
+			if cellType == "float" {
+				resultStyle.CellValueType = cellType
+			}
+			if cellType == "bool" {
+				resultStyle.CellValueType = cellType
+			}
+		case BackgroundColorAttrName:
+			resultStyle.BackgroundColor = excelColor(attr, value, resultStyle.BackgroundColor)
+
+		case BackgroundStyleAttr:
+			resultStyle.BackgroundColor = "" // shorthand without color resets background to transparent
 
+			if color, ok := css.FindColor(value); ok {
+				resultStyle.BackgroundColor = color.Blend(css.White).Hex()
 			}
 
+		case ColorStyleAttr:
+			resultStyle.Color = excelColor(attr, value, resultStyle.Color)
 
+		default:
+			if strings.HasPrefix(attr, BorderAttrName) {
+				applyBorderDeclaration(resultStyle, attr, value, fontPixels)
+			}
 		}
+
 	}
+
 	return resultStyle
 }
 
+// fontPoints Returns font size in points (rounded to hundredths) of size in css pixels
+func fontPoints(pixels float64) float64 {
+	return math.Round(pixels*css.PointsPerPixel*100) / 100
+}
+
+// applyTextDecoration Sets underline and strikethrough of text-decoration value: underline, line-through,
+// double underline. Lines not listed are removed
+func applyTextDecoration(style *types.HtmlStyle, value string) {
+	style.Underline = ""
+	style.IsStrike = false
+	isDouble := false
+
+	for _, token := range css.SplitValue(strings.ToLower(value)) {
+		switch token {
+		case "underline":
+			style.Underline = "single"
+		case "line-through":
+			style.IsStrike = true
+		case "double":
+			isDouble = true
+		}
+	}
+
+	if isDouble && style.Underline != "" {
+		style.Underline = "double"
+	}
+}
+
+// fontSizePixels Returns font size of declarations (font-size and font shorthand) in css pixels. Relative sizes are relative to font size
+// of declarations before them (inherited ones), default font size for the first one. Invalid sizes are ignored
+func fontSizePixels(declarations []css.Declaration) float64 {
+	size := DefaultFontSize / css.PointsPerPixel
+
+	for _, declaration := range declarations {
+		value := declaration.Value
+
+		if declaration.Property == FontStyleShorthandAttr {
+			font, err := css.ParseFont(value)
+
+			if err != nil {
+				continue // invalid shorthand is logged with other font properties
+			}
+
+			value = font.Size
+		} else if declaration.Property != FontSizeStyleAttr {
+			continue
+		}
+
+		pixels, err := css.FontSizePixels(value, size)
+
+		if err != nil {
+			log.WithError(err).Warnf("Length of %s ignored", FontSizeStyleAttr)
+			continue
+		}
+
+		size = pixels
+	}
+
+	return size
+}
+
+// lengthPixels Returns length value of property in css pixels. Em lengths are relative to fontPixels,
+// percentages to percentBase. Invalid lengths and percentages of unknown base are ignored with a warning
+func lengthPixels(property string, value string, fontPixels float64, percentBase float64) (float64, bool) {
+	if strings.EqualFold(strings.TrimSpace(value), "auto") {
+		return 0, false
+	}
+
+	length, err := css.ParseLength(value)
+
+	if err != nil {
+		log.WithError(err).Warnf("Length of %s ignored", property)
+		return 0, false
+	}
+
+	if length.Unit == "%" && percentBase <= 0 {
+		log.Warnf("Percentage %s of %s ignored: table width is unknown", value, property)
+		return 0, false
+	}
+
+	return length.Pixels(fontPixels, percentBase), true
+}
+
+// excelColor Returns excel color of css color value of property. Unknown colors are ignored with a warning,
+// previous value is returned
+func excelColor(property string, value string, previous string) string {
+	color, err := css.ExcelColor(value)
+
+	if err != nil {
+		log.WithError(err).Warnf("Color of %s ignored", property)
+		return previous
+	}
+
+	return color
+}
 
 // PrintMemUsage outputs the current, total and OS memory being used. As well as the number
 // of garage collection cycles completed.
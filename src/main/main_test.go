@@ -0,0 +1,59 @@
+package main
+
+import (
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/icewind666/html-to-excel-renderer/src/generator"
+	"testing"
+)
+
+func TestExtractStylesColors(t *testing.T) {
+	tests := []struct {
+		style      string
+		color      string
+		background string
+	}{
+		{"color: #f00; background-color: rgb(0, 0, 255)", "#FF0000", "#0000FF"},
+		{"color: navy; background-color: rgba(0, 0, 0, 0.5)", "#000080", "#808080"},
+		{"color: hsl(120, 100%, 25%); background: url(bg.png) #abc no-repeat", "#008000", "#AABBCC"},
+		{"background-color: red; background: none", "", ""},
+		{"color: red; color: reddish; background-color: #fff; background-color: #12", "#FF0000", "#FFFFFF"},
+		{"background-color: transparent", "", "#FFFFFF"},
+	}
+
+	for _, test := range tests {
+		style := ExtractStylesString(test.style)
+
+		if style.Color != test.color || style.BackgroundColor != test.background {
+			t.Errorf("%q colors are %q on %q, want %q on %q", test.style, style.Color, style.BackgroundColor,
+				test.color, test.background)
+		}
+	}
+}
+
+func TestExtractStylesFonts(t *testing.T) {
+	tests := []struct {
+		style string
+		want  excelize.Font
+	}{
+		{"font-family: 'PT Sans', sans-serif; font-size: 16px; font-weight: 700",
+			excelize.Font{Family: "PT Sans", Size: 12, Bold: true}},
+		{"font: italic bold 12pt/1.5 serif; color: #333",
+			excelize.Font{Family: "Times New Roman", Size: 12, Bold: true, Italic: true, Color: "#333333"}},
+		{"font: 20px monospace; font-weight: lighter; font-style: oblique",
+			excelize.Font{Family: "Courier New", Size: 15, Italic: true}},
+		{"font-size: 20px; font-size: 1.5em", excelize.Font{Size: 22.5}},
+		{"font-weight: bold; font: 16px Arial", excelize.Font{Family: "Arial", Size: 12}}, // shorthand resets weight
+		{"text-decoration: underline line-through", excelize.Font{Underline: "single", Strike: true}},
+		{"text-decoration: underline double; text-decoration-line: underline",
+			excelize.Font{Underline: "single"}}, // lines not listed are removed
+		{"font-weight: heavy; font: bold Arial", excelize.Font{}}, // invalid values are ignored, size is excel default
+	}
+
+	for _, test := range tests {
+		font := generator.ExcelizeFont(ExtractStylesString(test.style))
+
+		if *font != test.want {
+			t.Errorf("%q font is %+v, want %+v", test.style, *font, test.want)
+		}
+	}
+}
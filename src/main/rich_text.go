@@ -0,0 +1,96 @@
+package main
+
+import (
+	"github.com/icewind666/html-to-excel-renderer/src/css"
+	"github.com/jbowtie/gokogiri/xml"
+	"strconv"
+	"strings"
+)
+
+// elementStyles Default styles of html elements inside cells (like browser default stylesheet).
+// Excel makes superscript and subscript text smaller itself, so <sup> and <sub> keep font size
+var elementStyles = map[string]string{
+	"b":      "font-weight: bold",
+	"strong": "font-weight: bold",
+	"i":      "font-style: italic",
+	"em":     "font-style: italic",
+	"cite":   "font-style: italic",
+	"dfn":    "font-style: italic",
+	"var":    "font-style: italic",
+	"u":      "text-decoration: underline",
+	"ins":    "text-decoration: underline",
+	"s":      "text-decoration: line-through",
+	"strike": "text-decoration: line-through",
+	"del":    "text-decoration: line-through",
+	"sup":    "vertical-align: super",
+	"sub":    "vertical-align: sub",
+	"small":  "font-size: smaller",
+	"big":    "font-size: larger",
+	"code":   "font-family: monospace",
+	"kbd":    "font-family: monospace",
+	"samp":   "font-family: monospace",
+	"tt":     "font-family: monospace",
+	"pre":    "font-family: monospace",
+	"h1":     "font-weight: bold; font-size: 2em",
+	"h2":     "font-weight: bold; font-size: 1.5em",
+	"h3":     "font-weight: bold; font-size: 1.17em",
+	"h4":     "font-weight: bold",
+	"h5":     "font-weight: bold; font-size: 0.83em",
+	"h6":     "font-weight: bold; font-size: 0.67em",
+}
+
+// fontElementSizes Font sizes of size attribute of <font> element
+var fontElementSizes = []string{"x-small", "small", "medium", "large", "x-large", "xx-large", "xxx-large"}
+
+// elementDeclarations Returns default declarations of element and declarations of its
+// presentational attributes (color, face and size of <font>). Links (<a href>) get link style.
+// Element own styles override them
+func elementDeclarations(node xml.Node, name string) []css.Declaration {
+	declarations := css.ParseDeclarations(elementStyles[name])
+
+	if name == "a" && node.Attribute(HrefAttrName) != nil {
+		return css.ParseDeclarations(linkStyle)
+	}
+
+	if name != "font" {
+		return declarations
+	}
+
+	if color := node.Attr("color"); color != "" {
+		declarations = append(declarations, css.Declaration{Property: ColorStyleAttr, Value: color})
+	}
+
+	if face := node.Attr("face"); face != "" {
+		declarations = append(declarations, css.Declaration{Property: FontFamilyStyleAttr, Value: face})
+	}
+
+	if size, ok := fontElementSize(node.Attr("size")); ok {
+		declarations = append(declarations, css.Declaration{Property: FontSizeStyleAttr, Value: size})
+	}
+
+	return declarations
+}
+
+// fontElementSize Returns font size keyword of size attribute of <font>: 1-7 or relative to 3 (+1, -2)
+func fontElementSize(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	size, err := strconv.Atoi(strings.TrimPrefix(value, "+"))
+
+	if err != nil {
+		return "", false
+	}
+
+	if strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-") {
+		size += 3
+	}
+
+	if size < 1 {
+		size = 1
+	}
+
+	if size > len(fontElementSizes) {
+		size = len(fontElementSizes)
+	}
+
+	return fontElementSizes[size-1], true
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/jbowtie/gokogiri/xml"
+	log "github.com/sirupsen/logrus"
+	"strconv"
+	"strings"
+)
+
+// SheetAttrName Name of sheet table is placed on. Tables with the same data-sheet share one sheet
+const SheetAttrName = "data-sheet"
+
+// AnchorAttrName Top left cell of table on sheet (B5)
+const AnchorAttrName = "data-anchor"
+
+// GapAttrName Number of empty rows between table and table above it
+const GapAttrName = "data-gap"
+
+// defaultTableGap Empty rows between stacked tables
+const defaultTableGap = 1
+
+// sheetRegion Cells range taken by table on sheet. Row and Col are excel (one based) coordinates
+type sheetRegion struct {
+	Row  int
+	Col  int
+	Rows int
+	Cols int
+}
+
+// overlaps Checks regions have common cells
+func (r sheetRegion) overlaps(other sheetRegion) bool {
+	return r.Row < other.Row+other.Rows && other.Row < r.Row+r.Rows &&
+		r.Col < other.Col+other.Cols && other.Col < r.Col+r.Cols
+}
+
+// sheetPlacement Regions taken by tables placed on one sheet and totals sections of the tables
+// written under all tables of the sheet
+type sheetPlacement struct {
+	regions []sheetRegion
+	totals  []tableTotals
+}
+
+// tableTotals Totals sections of table deferred to the bottom of the sheet
+type tableTotals struct {
+	Layout   *tableLayout
+	Styles   *tableStyles
+	Sections []*layoutSection
+	Col      int
+}
+
+// Place Returns top left cell for table of rows x cols and takes its region. Table without anchor
+// is placed under the lowest table. Table overlapping tables placed before is moved down below them
+func (p *sheetPlacement) Place(table xml.Node, rows int, cols int) (int, int) {
+	gap := tableGap(table)
+	region := sheetRegion{Row: 1, Col: 1, Rows: rows, Cols: cols}
+
+	if anchorRow, anchorCol, ok := tableAnchor(table); ok {
+		region.Row, region.Col = anchorRow, anchorCol
+	} else if bottom := p.bottom(); bottom > 0 {
+		region.Row = bottom + gap + 1
+	}
+
+	region = p.take(region, gap, true)
+	return region.Row, region.Col
+}
+
+// DeferTotals Keeps totals sections of table placed at column until all tables of the sheet are placed
+func (p *sheetPlacement) DeferTotals(totals tableTotals) {
+	p.totals = append(p.totals, totals)
+}
+
+// PlaceTotals Returns deferred totals sections with excel rows they start at and takes their regions.
+// Totals are placed right under the lowest table at columns of their tables, totals sharing columns are stacked
+func (p *sheetPlacement) PlaceTotals() ([]tableTotals, []int) {
+	top := p.bottom() + 1
+	rows := make([]int, len(p.totals))
+
+	for i, totals := range p.totals {
+		start := totals.Layout.TotalsStart()
+		region := sheetRegion{Row: top, Col: totals.Col, Rows: totals.Layout.Height() - start,
+			Cols: totals.Layout.Width()}
+		rows[i] = p.take(region, 0, false).Row
+	}
+
+	placed := p.totals
+	p.totals = nil
+	return placed, rows
+}
+
+// take Moves region down below regions it overlaps and takes it. Moves are logged for tables placed
+// at their anchors
+func (p *sheetPlacement) take(region sheetRegion, gap int, warn bool) sheetRegion {
+	for moved := true; moved; {
+		moved = false
+
+		for _, placed := range p.regions {
+			if region.overlaps(placed) {
+				if warn {
+					log.Warnf("Table at %s overlaps table placed before. Moved down", cellName(region.Row, region.Col))
+				}
+
+				region.Row = placed.Row + placed.Rows + gap
+				moved = true
+			}
+		}
+	}
+
+	if region.Rows > 0 && region.Cols > 0 {
+		p.regions = append(p.regions, region)
+	}
+
+	return region
+}
+
+// bottom Returns last row taken by tables, 0 for empty sheet
+func (p *sheetPlacement) bottom() int {
+	bottom := 0
+
+	for _, region := range p.regions {
+		if last := region.Row + region.Rows - 1; last > bottom {
+			bottom = last
+		}
+	}
+
+	return bottom
+}
+
+// tableAnchor Returns row and column of data-anchor cell. Invalid anchors are ignored
+func tableAnchor(table xml.Node) (int, int, bool) {
+	anchor := strings.TrimSpace(table.Attr(AnchorAttrName))
+
+	if anchor == "" {
+		return 0, 0, false
+	}
+
+	col, row, err := excelize.CellNameToCoordinates(strings.ToUpper(anchor))
+
+	if err != nil {
+		log.WithError(err).Warnf("Invalid %s %q. Table is placed under tables above", AnchorAttrName, anchor)
+		return 0, 0, false
+	}
+
+	return row, col, true
+}
+
+// tableGap Returns data-gap of table or default gap
+func tableGap(table xml.Node) int {
+	gap, err := strconv.Atoi(strings.TrimSpace(table.Attr(GapAttrName)))
+
+	if err != nil || gap < 0 {
+		return defaultTableGap
+	}
+
+	return gap
+}
+
+// cellName Returns excel cell name of row and column (for logs)
+func cellName(row int, col int) string {
+	name, _ := excelize.CoordinatesToCellName(col, row)
+	return name
+}
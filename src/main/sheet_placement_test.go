@@ -0,0 +1,95 @@
+package main
+
+import (
+	"github.com/jbowtie/gokogiri"
+	"github.com/jbowtie/gokogiri/xml"
+	"testing"
+)
+
+// parseTables Returns tables of html document
+func parseTables(t *testing.T, html string) []xml.Node {
+	doc, err := gokogiri.ParseHtml([]byte(html))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(doc.Free)
+	tables, _ := doc.Root().Search(XpathTable)
+	return tables
+}
+
+func TestSheetPlacementPlacesTotalsUnderLowestTable(t *testing.T) {
+	tables := parseTables(t, `<html><body>
+		<table><tr><td>a</td></tr><tfoot data-totals><tr><td>a total</td></tr></tfoot><tr><td>a</td></tr></table>
+		<table data-anchor="C1"><tr><td>b</td></tr><tr><td>b</td></tr><tr><td>b</td></tr>
+			<tfoot data-totals><tr><td>b total</td></tr><tr><td>b total</td></tr></tfoot></table>
+		<table data-anchor="A4"><tr><td>c</td></tr></table>
+	</body></html>`)
+
+	placement := &sheetPlacement{}
+	var layouts []*tableLayout
+
+	for _, table := range tables {
+		layout := layoutTable(table, NestedTablesGrid)
+		row, col := placement.Place(table, layout.TotalsStart(), layout.Width())
+		layouts = append(layouts, layout)
+
+		for _, section := range layout.Sections {
+			if section.Section.Totals {
+				placement.DeferTotals(tableTotals{Layout: layout, Sections: []*layoutSection{section}, Col: col})
+			}
+		}
+
+		if row != []int{1, 1, 4}[len(layouts)-1] {
+			t.Errorf("table %d placed at row %d", len(layouts), row)
+		}
+	}
+
+	if layouts[0].TotalsStart() != 2 || layouts[1].TotalsStart() != 3 {
+		t.Errorf("totals start at %d and %d, want 2 and 3", layouts[0].TotalsStart(), layouts[1].TotalsStart())
+	}
+
+	totals, rows := placement.PlaceTotals()
+
+	if len(totals) != 2 || rows[0] != 5 || rows[1] != 5 {
+		t.Errorf("totals placed at rows %v, want both at 5 under table at A4", rows)
+	}
+
+	if bottom := placement.bottom(); bottom != 6 {
+		t.Errorf("sheet bottom is %d after totals, want 6", bottom)
+	}
+}
+
+func TestSheetPlacementPushesOverlappingTablesDown(t *testing.T) {
+	tables := parseTables(t, `<html><body>
+		<table></table>
+		<table data-anchor="D2"></table>
+		<table data-gap="3"></table>
+		<table data-anchor="b3"></table>
+		<table data-anchor="A1" data-gap="0"></table>
+		<table data-anchor="not a cell"></table>
+		<table data-anchor="D1"></table>
+	</body></html>`)
+
+	sizes := [][2]int{{3, 2}, {4, 2}, {2, 5}, {2, 2}, {1, 1}, {1, 1}, {4, 2}} // rows, cols
+	want := [][2]int{
+		{1, 1},  // empty sheet
+		{2, 4},  // anchor, no overlap with the first table at A1:B3
+		{9, 1},  // under the lowest table (D2:E5) with gap 3
+		{5, 2},  // anchor B3 overlaps A1:B3, moved below it with default gap
+		{4, 1},  // anchor A1 overlaps A1:B3, moved right under it with gap 0
+		{12, 1}, // invalid anchor is placed under the lowest table (A9:E10)
+		{12, 4}, // anchor D1 overlaps D2:E5, then A9:E10 after moving down
+	}
+
+	placement := &sheetPlacement{}
+
+	for i, table := range tables {
+		row, col := placement.Place(table, sizes[i][0], sizes[i][1])
+
+		if row != want[i][0] || col != want[i][1] {
+			t.Errorf("table %d placed at %s, want %s", i+1, cellName(row, col), cellName(want[i][0], want[i][1]))
+		}
+	}
+}
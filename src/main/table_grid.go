@@ -0,0 +1,117 @@
+package main
+
+import (
+	"github.com/jbowtie/gokogiri/xml"
+	"strconv"
+	"strings"
+)
+
+// RowspanAttrName Rowspan attribute name
+const RowspanAttrName = "rowspan"
+
+// maxColspan Max colspan honored by html table layout algorithm
+const maxColspan = 1000
+
+// maxRowspan Max rowspan honored by html table layout algorithm
+const maxRowspan = 65534
+
+// gridCell Html table cell placed on table grid. Row and Col are zero based
+type gridCell struct {
+	Node    xml.Node
+	Row     int
+	Col     int
+	Rowspan int
+	Colspan int
+}
+
+// tableGrid Occupancy grid of html table built like browser table layout algorithm does.
+// Cell is placed to the first column of its row not occupied by cells spanning from rows above
+type tableGrid struct {
+	row      int                  // next grid row
+	groupEnd int                  // first grid row after current row group
+	occupied map[int]map[int]bool // grid row -> columns occupied by cells of rows above
+}
+
+// newTableGrid Creates empty table grid
+func newTableGrid() *tableGrid {
+	return &tableGrid{occupied: make(map[int]map[int]bool)}
+}
+
+// StartRowGroup Starts row group (thead, tbody, tfoot) of rowCount rows. Rowspans are clipped
+// to the end of row group, rowspan="0" spans to the end of row group
+func (g *tableGrid) StartRowGroup(rowCount int) {
+	g.occupied = make(map[int]map[int]bool) // cells of previous group cant span into this one
+	g.groupEnd = g.row + rowCount
+}
+
+// PlaceRow Places cells of next table row on grid and returns them with their grid positions
+func (g *tableGrid) PlaceRow(cells []xml.Node) []gridCell {
+	row := g.row
+	g.row++
+
+	if row >= g.groupEnd {
+		g.groupEnd = row + 1 // row outside of any known group
+	}
+
+	occupied := g.occupied[row]
+	delete(g.occupied, row)
+
+	result := make([]gridCell, 0, len(cells))
+	col := 0
+
+	for _, cell := range cells {
+		for occupied[col] {
+			col++
+		}
+
+		colspan := spanAttribute(cell, ColspanAttrName, 1, maxColspan)
+		rowspan := spanAttribute(cell, RowspanAttrName, 1, maxRowspan)
+
+		if rowspan == 0 || row+rowspan > g.groupEnd {
+			rowspan = g.groupEnd - row
+		}
+
+		for r := row + 1; r < row+rowspan; r++ {
+			if g.occupied[r] == nil {
+				g.occupied[r] = make(map[int]bool)
+			}
+
+			for c := col; c < col+colspan; c++ {
+				g.occupied[r][c] = true
+			}
+		}
+
+		result = append(result, gridCell{Node: cell, Row: row, Col: col, Rowspan: rowspan, Colspan: colspan})
+		col += colspan
+	}
+
+	return result
+}
+
+// spanAttribute Parses colspan or rowspan attribute like browsers do: leading digits are used ("2px" is 2).
+// Missing and invalid values are defaultValue, values are limited by maxValue. Only rowspan can be 0
+func spanAttribute(cell xml.Node, name string, defaultValue int, maxValue int) int {
+	attr := cell.Attribute(name)
+
+	if attr == nil {
+		return defaultValue
+	}
+
+	digits := strings.TrimSpace(attr.Value())
+
+	if end := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+		digits = digits[:end]
+	}
+
+	value, err := strconv.Atoi(digits)
+
+	if err != nil || (value == 0 && name != RowspanAttrName) {
+		return defaultValue
+	}
+
+	if value > maxValue {
+		return maxValue
+	}
+
+	return value
+}
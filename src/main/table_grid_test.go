@@ -0,0 +1,121 @@
+package main
+
+import (
+	"testing"
+)
+
+// placedCell Grid position of cell by its text
+type placedCell struct {
+	Text    string
+	Row     int
+	Col     int
+	Rowspan int
+	Colspan int
+}
+
+// placeTable Places rows of the first table in html on grid as one row group, or as row groups of given sizes
+func placeTable(t *testing.T, html string, groups ...int) []placedCell {
+	rows, _ := parseTables(t, html)[0].Search(".//tr")
+	grid := newTableGrid()
+
+	if len(groups) == 0 {
+		groups = []int{len(rows)}
+	}
+
+	var result []placedCell
+
+	for _, group := range groups {
+		grid.StartRowGroup(group)
+
+		for _, tr := range rows[:group] {
+			cells, _ := tr.Search(XpathRowCells)
+
+			for _, cell := range grid.PlaceRow(cells) {
+				result = append(result, placedCell{cell.Node.Content(), cell.Row, cell.Col, cell.Rowspan, cell.Colspan})
+			}
+		}
+
+		rows = rows[group:]
+	}
+
+	return result
+}
+
+func TestTableGridPlacesCellsAroundSpans(t *testing.T) {
+	tests := []struct {
+		name   string
+		html   string
+		groups []int
+		want   []placedCell
+	}{
+		{
+			name: "rowspan moves cells below to next free column",
+			html: `<table><tr><td rowspan="2">a</td><td>b</td><td>c</td></tr><tr><td>d</td><td>e</td></tr></table>`,
+			want: []placedCell{{"a", 0, 0, 2, 1}, {"b", 0, 1, 1, 1}, {"c", 0, 2, 1, 1}, {"d", 1, 1, 1, 1},
+				{"e", 1, 2, 1, 1}},
+		},
+		{
+			name: "rowspan and colspan block",
+			html: `<table><tr><td>a</td><td rowspan="2" colspan="2">b</td><td>c</td></tr>` +
+				`<tr><td>d</td><td>e</td></tr><tr><td>f</td><td>g</td></tr></table>`,
+			want: []placedCell{{"a", 0, 0, 1, 1}, {"b", 0, 1, 2, 2}, {"c", 0, 3, 1, 1}, {"d", 1, 0, 1, 1},
+				{"e", 1, 3, 1, 1}, {"f", 2, 0, 1, 1}, {"g", 2, 1, 1, 1}},
+		},
+		{
+			name: "colspan overlapping rowspan from above is kept where it starts",
+			html: `<table><tr><td>a</td><td rowspan="2">b</td></tr><tr><td colspan="3">c</td><td>d</td></tr></table>`,
+			want: []placedCell{{"a", 0, 0, 1, 1}, {"b", 0, 1, 2, 1}, {"c", 1, 0, 1, 3}, {"d", 1, 3, 1, 1}},
+		},
+		{
+			name: "overlapping rowspans skip all occupied columns",
+			html: `<table><tr><td rowspan="3">a</td><td rowspan="2">b</td><td>c</td></tr>` +
+				`<tr><td>d</td></tr><tr><td>e</td><td>f</td></tr></table>`,
+			want: []placedCell{{"a", 0, 0, 3, 1}, {"b", 0, 1, 2, 1}, {"c", 0, 2, 1, 1}, {"d", 1, 2, 1, 1},
+				{"e", 2, 1, 1, 1}, {"f", 2, 2, 1, 1}},
+		},
+		{
+			name: "rowspan is clipped to row group and zero spans to its end",
+			html: `<table><tr><td rowspan="5">a</td><td rowspan="0">b</td></tr><tr><td>c</td></tr>` +
+				`<tr><td>d</td><td>e</td></tr></table>`,
+			groups: []int{2, 1},
+			want: []placedCell{{"a", 0, 0, 2, 1}, {"b", 0, 1, 2, 1}, {"c", 1, 2, 1, 1}, {"d", 2, 0, 1, 1},
+				{"e", 2, 1, 1, 1}},
+		},
+		{
+			name: "invalid spans",
+			html: `<table><tr><td colspan="0">a</td><td colspan="2px">b</td><td colspan="x">c</td></tr></table>`,
+			want: []placedCell{{"a", 0, 0, 1, 1}, {"b", 0, 1, 1, 2}, {"c", 0, 3, 1, 1}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := placeTable(t, test.html, test.groups...)
+
+			if len(got) != len(test.want) {
+				t.Fatalf("placed %v, want %v", got, test.want)
+			}
+
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("cell %s placed at %+v, want %+v", got[i].Text, got[i], test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLayoutTableSizes(t *testing.T) {
+	table := parseTables(t, `<html><body><table>
+		<thead><tr><th colspan="3">title</th></tr></thead>
+		<tbody><tr><td rowspan="2">a</td><td>b</td><td>c</td></tr><tr><td>d</td></tr></tbody>
+		<tfoot data-totals><tr><td>total</td></tr></tfoot>
+	</table></body></html>`)[0]
+
+	layout := layoutTable(table, NestedTablesGrid)
+
+	if layout.Height() != 4 || layout.Width() != 3 || layout.TotalsStart() != 3 {
+		t.Errorf("layout %dx%d with totals at %d, want 4x3 with totals at 3", layout.Height(), layout.Width(),
+			layout.TotalsStart())
+	}
+}
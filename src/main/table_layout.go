@@ -0,0 +1,239 @@
+package main
+
+import (
+	"github.com/jbowtie/gokogiri/xml"
+)
+
+// Nested table modes
+const (
+	NestedTablesGrid  = "grid"  // nested table is laid out inside containing cell
+	NestedTablesSheet = "sheet" // nested table is written to its own sheet, containing cell links to it
+)
+
+// NestedAttrName Overrides nested tables mode (--nested-tables) for one nested table
+const NestedAttrName = "data-nested"
+
+// tableLayout Html table laid out on excel cells. Grid rows and columns are expanded
+// to fit tables nested in their cells
+type tableLayout struct {
+	Table     xml.Node
+	Sections  []*layoutSection
+	rowStarts []int // first excel row (relative to table) of grid row, last item is table height
+	colStarts []int // first excel column (relative to table) of grid column, last item is table width
+}
+
+// layoutSection Table section with rows placed on table grid
+type layoutSection struct {
+	Section *tableSection
+	Rows    []layoutRow
+}
+
+// layoutRow Table row with cells placed on table grid
+type layoutRow struct {
+	Node    xml.Node
+	GridRow int
+	Cells   []layoutCell
+}
+
+// layoutCell Table cell placed on table grid with tables nested in it
+type layoutCell struct {
+	gridCell
+	Nested       []*tableLayout // tables laid out inside cell, one under another
+	NestedSheets []xml.Node     // tables written to their own sheets
+}
+
+// HasNested Checks cell contains nested tables. Content of such cells is nested tables only
+func (c *layoutCell) HasNested() bool {
+	return len(c.Nested) > 0 || len(c.NestedSheets) > 0
+}
+
+// layoutTable Lays out table sections on grid and expands grid rows and columns to fit nested tables.
+// Totals sections are placed last
+func layoutTable(table xml.Node, nestedMode string) *tableLayout {
+	layout := &tableLayout{Table: table}
+	grid := newTableGrid() // spans don't cross sections
+	var totals []*layoutSection
+
+	for _, section := range tableSections(table) {
+		placed := &layoutSection{Section: section, Rows: make([]layoutRow, 0, len(section.Rows))}
+
+		if section.Totals {
+			totals = append(totals, placed) // placed at the bottom of the table or sheet
+		} else {
+			layout.Sections = append(layout.Sections, placed)
+		}
+	}
+
+	layout.Sections = append(layout.Sections, totals...)
+	var rowHeights, colWidths []int // excel rows and columns of grid row and column
+
+	for _, section := range layout.Sections {
+		grid.StartRowGroup(len(section.Section.Rows))
+
+		for _, tr := range section.Section.Rows {
+			cells, _ := tr.Search(XpathRowCells)
+			row := layoutRow{Node: tr, GridRow: grid.row}
+
+			for _, cell := range grid.PlaceRow(cells) {
+				placed := layoutCell{gridCell: cell}
+
+				for _, nested := range nestedTables(cell.Node) {
+					if nestedTableMode(nested, nestedMode) == NestedTablesSheet {
+						placed.NestedSheets = append(placed.NestedSheets, nested)
+					} else {
+						placed.Nested = append(placed.Nested, layoutTable(nested, nestedMode))
+					}
+				}
+
+				rowHeights = growSizes(rowHeights, cell.Row+cell.Rowspan)
+				colWidths = growSizes(colWidths, cell.Col+cell.Colspan)
+				row.Cells = append(row.Cells, placed)
+			}
+
+			rowHeights = growSizes(rowHeights, grid.row)
+			section.Rows = append(section.Rows, row)
+		}
+	}
+
+	// cells spanning one row or column set its size first, spanning cells extend their last row or column
+	for _, spanning := range []bool{false, true} {
+		for _, section := range layout.Sections {
+			for _, row := range section.Rows {
+				for _, cell := range row.Cells {
+					if len(cell.Nested) == 0 {
+						continue
+					}
+
+					height, width := cell.nestedSize()
+
+					if (cell.Rowspan > 1) == spanning {
+						fitSize(rowHeights, cell.Row, cell.Rowspan, height)
+					}
+
+					if (cell.Colspan > 1) == spanning {
+						fitSize(colWidths, cell.Col, cell.Colspan, width)
+					}
+				}
+			}
+		}
+	}
+
+	layout.rowStarts = sizeStarts(rowHeights)
+	layout.colStarts = sizeStarts(colWidths)
+	return layout
+}
+
+// Height Returns number of excel rows taken by table
+func (l *tableLayout) Height() int {
+	return l.rowStarts[len(l.rowStarts)-1]
+}
+
+// Width Returns number of excel columns taken by table
+func (l *tableLayout) Width() int {
+	return l.colStarts[len(l.colStarts)-1]
+}
+
+// CellRange Returns first excel row and column (relative to table) of cell and number of rows and columns it takes
+func (l *tableLayout) CellRange(cell gridCell) (row int, col int, rows int, cols int) {
+	row = l.rowStarts[cell.Row]
+	col = l.colStarts[cell.Col]
+	rows = l.rowStarts[cell.Row+cell.Rowspan] - row
+	cols = l.colStarts[cell.Col+cell.Colspan] - col
+	return row, col, rows, cols
+}
+
+// TotalsStart Returns first excel row (relative to table) of totals sections, which is table height
+// without totals. Totals sections are the last ones
+func (l *tableLayout) TotalsStart() int {
+	for _, section := range l.Sections {
+		if section.Section.Totals && len(section.Rows) > 0 {
+			return l.RowStart(section.Rows[0].GridRow)
+		}
+	}
+
+	return l.Height()
+}
+
+// RowStart Returns first excel row (relative to table) of grid row
+func (l *tableLayout) RowStart(gridRow int) int {
+	return l.rowStarts[gridRow]
+}
+
+// nestedSize Returns excel rows and columns taken by nested tables placed one under another
+func (c *layoutCell) nestedSize() (int, int) {
+	height, width := 0, 0
+
+	for _, nested := range c.Nested {
+		height += nested.Height()
+
+		if nested.Width() > width {
+			width = nested.Width()
+		}
+	}
+
+	return height, width
+}
+
+// nestedTables Returns tables nested in cell. Tables nested in them are not included
+func nestedTables(node xml.Node) []xml.Node {
+	var result []xml.Node
+
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if child.NodeType() != xml.XML_ELEMENT_NODE {
+			continue
+		}
+
+		if child.Name() == "table" {
+			result = append(result, child)
+		} else {
+			result = append(result, nestedTables(child)...)
+		}
+	}
+
+	return result
+}
+
+// nestedTableMode Returns mode of nested table: data-nested attribute or default mode
+func nestedTableMode(table xml.Node, defaultMode string) string {
+	switch table.Attr(NestedAttrName) {
+	case NestedTablesGrid:
+		return NestedTablesGrid
+	case NestedTablesSheet:
+		return NestedTablesSheet
+	}
+
+	return defaultMode
+}
+
+// growSizes Extends sizes to count items of size 1
+func growSizes(sizes []int, count int) []int {
+	for len(sizes) < count {
+		sizes = append(sizes, 1)
+	}
+
+	return sizes
+}
+
+// fitSize Extends last of count sizes from start so their sum is at least size
+func fitSize(sizes []int, start int, count int, size int) {
+	total := 0
+
+	for i := start; i < start+count; i++ {
+		total += sizes[i]
+	}
+
+	if total < size {
+		sizes[start+count-1] += size - total
+	}
+}
+
+// sizeStarts Returns start of every item and total size as last item
+func sizeStarts(sizes []int) []int {
+	starts := make([]int, len(sizes)+1)
+
+	for i, size := range sizes {
+		starts[i+1] = starts[i] + size
+	}
+
+	return starts
+}
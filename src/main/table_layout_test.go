@@ -0,0 +1,173 @@
+package main
+
+import (
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLayoutTableNestedTables(t *testing.T) {
+	html := `<table>
+		<tr><td>a</td><td>
+			<table><tr><td>1</td><td>2</td><td>3</td></tr><tr><td>4</td><td>5</td><td>6</td></tr></table>
+			<div><table data-nested="sheet"><tr><td>details</td></tr></table></div>
+			<table><tr><td colspan="2">7</td></tr></table>
+		</td><td>b</td></tr>
+		<tr><td colspan="3">c</td></tr>
+	</table>`
+
+	tests := []struct {
+		name         string
+		mode         string
+		height       int
+		width        int
+		nested       int
+		nestedSheets int
+		bRange       [4]int // row, col, rows, cols of cell b
+	}{
+		{"grid", NestedTablesGrid, 4, 5, 2, 1, [4]int{0, 4, 3, 1}},
+		{"sheet", NestedTablesSheet, 2, 3, 0, 3, [4]int{0, 2, 1, 1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			layout := layoutTable(parseTables(t, html)[0], test.mode)
+
+			if layout.Height() != test.height || layout.Width() != test.width {
+				t.Errorf("layout is %dx%d, want %dx%d", layout.Height(), layout.Width(), test.height, test.width)
+			}
+
+			cells := layout.Sections[0].Rows[0].Cells
+
+			if len(cells[1].Nested) != test.nested || len(cells[1].NestedSheets) != test.nestedSheets {
+				t.Errorf("cell has %d nested tables and %d nested sheets, want %d and %d", len(cells[1].Nested),
+					len(cells[1].NestedSheets), test.nested, test.nestedSheets)
+			}
+
+			if cells[0].HasNested() || !cells[1].HasNested() {
+				t.Errorf("only the second cell has nested tables")
+			}
+
+			row, col, rows, cols := layout.CellRange(cells[2].gridCell)
+
+			if got := [4]int{row, col, rows, cols}; got != test.bRange {
+				t.Errorf("cell b takes %v, want %v", got, test.bRange)
+			}
+
+			row, col, rows, cols = layout.CellRange(layout.Sections[0].Rows[1].Cells[0].gridCell)
+
+			if row != test.height-1 || col != 0 || rows != 1 || cols != test.width {
+				t.Errorf("cell c takes %v, want the last row of %d columns", [4]int{row, col, rows, cols}, test.width)
+			}
+		})
+	}
+}
+
+func TestLayoutTableNestedInSpanningCell(t *testing.T) {
+	table := parseTables(t, `<table>
+		<tr><td rowspan="2"><table><tr><td>1</td></tr><tr><td>2</td></tr><tr><td>3</td></tr></table></td><td>a</td></tr>
+		<tr><td>b</td></tr>
+	</table>`)[0]
+
+	layout := layoutTable(table, NestedTablesGrid)
+
+	// spanning cell extends its last row
+	if layout.Height() != 3 || layout.RowStart(1) != 1 {
+		t.Errorf("layout height %d with second row at %d, want 3 and 1", layout.Height(), layout.RowStart(1))
+	}
+
+	if _, _, rows, _ := layout.CellRange(layout.Sections[0].Rows[1].Cells[0].gridCell); rows != 2 {
+		t.Errorf("cell b takes %d rows, want 2", rows)
+	}
+}
+
+func TestNestedTableMode(t *testing.T) {
+	tables := parseTables(t, `<table><tr><td>
+		<table data-nested="grid"><tr><td><table></table></td></tr></table>
+		<table data-nested="sheet"></table><p><table data-nested="other"></table></p>
+	</td></tr></table>`)
+	nested := nestedTables(tables[0])
+	want := []string{NestedTablesGrid, NestedTablesSheet, NestedTablesSheet}
+
+	if len(nested) != len(want) {
+		t.Fatalf("%d nested tables, want %d without tables nested in them", len(nested), len(want))
+	}
+
+	for i, table := range nested {
+		if mode := nestedTableMode(table, NestedTablesSheet); mode != want[i] {
+			t.Errorf("table %d mode is %s, want %s", i, mode, want[i])
+		}
+	}
+
+	if mode := nestedTableMode(nested[2], NestedTablesGrid); mode != NestedTablesGrid {
+		t.Errorf("table with unknown mode has mode %s, want default", mode)
+	}
+}
+
+// generateWorkbook Writes html tables to workbook in temporary directory and opens it
+func generateWorkbook(t *testing.T, html string) *excelize.File {
+	dir, err := ioutil.TempDir("", "workbook")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	workbook, err := excelize.OpenFile(generateXlsxFile(html, filepath.Join(dir, "report.xlsx"), 100))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return workbook
+}
+
+func TestNestedTablesWrittenInGridOrLinkedSheets(t *testing.T) {
+	html := `<table data-name="Report"><tr><td>a</td><td>
+		<table><tr><td>1</td><td>2</td></tr></table>
+		<table data-nested="sheet" data-name="Details"><tr><td>details</td></tr></table>
+	</td></tr></table>`
+
+	saved := opts.NestedTables
+	defer func() { opts.NestedTables = saved }()
+
+	tests := []struct {
+		mode   string
+		sheets []string
+		values map[string]string // values by cell of the first sheet
+		links  map[string]string // link locations by cell of the first sheet
+	}{
+		{NestedTablesGrid, []string{"Report", "Details"},
+			map[string]string{"A1": "a", "B1": "1", "C1": "2"},
+			map[string]string{"B2": "'Details'!A1"}},
+		{NestedTablesSheet, []string{"Report", "Report nested", "Details"},
+			map[string]string{"A1": "a", "B1": "Report nested", "B2": "Details"},
+			map[string]string{"B1": "'Report nested'!A1", "B2": "'Details'!A1"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.mode, func(t *testing.T) {
+			opts.NestedTables = test.mode
+			workbook := generateWorkbook(t, html)
+
+			if sheets := workbook.GetSheetList(); !reflect.DeepEqual(sheets, test.sheets) {
+				t.Errorf("sheets are %q, want %q", sheets, test.sheets)
+			}
+
+			for cell, want := range test.values {
+				if value, _ := workbook.GetCellValue("Report", cell); value != want {
+					t.Errorf("%s is %q, want %q", cell, value, want)
+				}
+			}
+
+			for cell, want := range test.links {
+				if linked, location, _ := workbook.GetCellHyperLink("Report", cell); !linked || location != want {
+					t.Errorf("%s links to %q, want %q", cell, location, want)
+				}
+			}
+		})
+	}
+}
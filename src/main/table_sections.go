@@ -0,0 +1,73 @@
+package main
+
+import (
+	"github.com/jbowtie/gokogiri/xml"
+	"github.com/jbowtie/gokogiri/xpath"
+)
+
+// Table row group kinds
+const (
+	TheadSection = "thead"
+	TbodySection = "tbody"
+	TfootSection = "tfoot"
+)
+
+// TotalsAttrName Marks tfoot as totals block: it is written at the bottom of the sheet, under all tables
+// placed on the sheet. Tables nested in cells keep totals at their bottom
+const TotalsAttrName = "data-totals"
+
+// XpathTableSections Row groups and rows outside of them in document order
+var XpathTableSections = xpath.Compile("./thead|./tbody|./tfoot|./tr")
+
+// tableSection Row group of html table (thead, tbody or tfoot)
+type tableSection struct {
+	Kind   string
+	Node   xml.Node // nil for implicit tbody
+	Rows   []xml.Node
+	Totals bool
+}
+
+// IsHeader Checks section is table header. Header cells are always styled and set column styles
+func (s *tableSection) IsHeader() bool {
+	return s.Kind == TheadSection
+}
+
+// tableSections Returns row groups of table in rendering order: thead, tbody sections in document order, tfoot.
+// Rows outside of sections form implicit tbody sections
+func tableSections(table xml.Node) []*tableSection {
+	nodes, _ := table.Search(XpathTableSections)
+
+	var heads, bodies, feet []*tableSection
+	var implicitBody *tableSection
+
+	for _, node := range nodes {
+		if node.Name() == "tr" {
+			if implicitBody == nil {
+				implicitBody = &tableSection{Kind: TbodySection}
+				bodies = append(bodies, implicitBody)
+			}
+
+			implicitBody.Rows = append(implicitBody.Rows, node)
+			continue
+		}
+
+		implicitBody = nil // rows after section start new implicit body
+		rows, _ := node.Search(XpathTr)
+		section := &tableSection{Kind: node.Name(), Node: node, Rows: rows}
+
+		switch section.Kind {
+		case TheadSection:
+			heads = append(heads, section)
+		case TfootSection:
+			section.Totals = node.Attribute(TotalsAttrName) != nil
+			feet = append(feet, section)
+		default:
+			bodies = append(bodies, section)
+		}
+	}
+
+	result := make([]*tableSection, 0, len(heads)+len(bodies)+len(feet))
+	result = append(result, heads...)
+	result = append(result, bodies...)
+	return append(result, feet...)
+}
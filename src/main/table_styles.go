@@ -0,0 +1,107 @@
+package main
+
+import (
+	"github.com/icewind666/html-to-excel-renderer/src/css"
+	"github.com/jbowtie/gokogiri/xml"
+	"github.com/jbowtie/gokogiri/xpath"
+)
+
+// SpanAttrName Number of columns taken by <col> or <colgroup>
+const SpanAttrName = "span"
+
+// XpathTableColumns Column groups and columns of table in document order
+var XpathTableColumns = xpath.Compile("./colgroup|./col")
+
+// XpathCol Columns of column group
+var XpathCol = xpath.Compile("./col")
+
+// tableStyles Computed declarations of table and its columns. Cells inherit them through
+// table -> column group -> column -> row group -> row -> cell chain
+type tableStyles struct {
+	matcher *css.Matcher
+	table   []css.Declaration
+	columns [][]css.Declaration // by grid column, columns without <col> inherit table declarations
+	width   float64             // table width in css pixels, percentage widths of cells are relative to it
+	hints   []css.Declaration   // declarations of table attributes applied to cells under their own declarations
+}
+
+// newTableStyles Computes declarations of table and its columns. Table inherits declarations
+// of cell it is nested in (nil for top level tables)
+func newTableStyles(table xml.Node, matcher *css.Matcher, inherited []css.Declaration) *tableStyles {
+	own := matcher.Declarations(table)
+	styles := &tableStyles{matcher: matcher, table: css.Inherit(inherited, own), width: tableWidth(table, own)}
+	styles.hints = tableBorderHints(table)
+	nodes, _ := table.Search(XpathTableColumns)
+
+	for _, node := range nodes {
+		computed := css.Inherit(styles.table, matcher.Declarations(node))
+
+		if node.Name() == "col" {
+			styles.addColumns(computed, spanAttribute(node, SpanAttrName, 1, maxColspan))
+			continue
+		}
+
+		cols, _ := node.Search(XpathCol)
+
+		if len(cols) == 0 {
+			styles.addColumns(computed, spanAttribute(node, SpanAttrName, 1, maxColspan))
+		}
+
+		for _, col := range cols {
+			styles.addColumns(css.Inherit(computed, matcher.Declarations(col)), spanAttribute(col, SpanAttrName, 1, maxColspan))
+		}
+	}
+
+	return styles
+}
+
+// tableWidth Returns table width in css pixels set by width style or attribute, 0 when unknown or relative
+func tableWidth(table xml.Node, declarations []css.Declaration) float64 {
+	value := table.Attr(WidthStyleAttr)
+
+	if declaration, ok := css.LastDeclaration(declarations, WidthStyleAttr); ok {
+		value = declaration.Value
+	}
+
+	length, err := css.ParseLength(value)
+
+	if err != nil || length.IsRelative() {
+		return 0
+	}
+
+	return length.Pixels(0, 0)
+}
+
+// addColumns Adds span columns with the same declarations
+func (s *tableStyles) addColumns(declarations []css.Declaration, span int) {
+	for i := 0; i < span && len(s.columns) < maxColspan; i++ {
+		s.columns = append(s.columns, declarations)
+	}
+}
+
+// Own Returns declarations of element itself (stylesheet rules and style attribute), nil for missing element
+func (s *tableStyles) Own(node xml.Node) []css.Declaration {
+	if node == nil {
+		return nil
+	}
+
+	return s.matcher.Declarations(node)
+}
+
+// Cell Returns computed declarations of cell starting at grid column col of row with given own declarations
+// of row group and row
+func (s *tableStyles) Cell(cell xml.Node, col int, section []css.Declaration, row []css.Declaration) []css.Declaration {
+	column := s.table
+
+	if col < len(s.columns) {
+		column = s.columns[col]
+	}
+
+	own := s.matcher.Declarations(cell)
+
+	if len(s.hints) > 0 {
+		own = append(append([]css.Declaration{}, s.hints...), own...)
+	}
+
+	return css.Inherit(css.Inherit(css.Inherit(column, section), row), own)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"github.com/icewind666/html-to-excel-renderer/src/css"
+	"testing"
+)
+
+func TestTableStylesCellInheritance(t *testing.T) {
+	table := parseTables(t, `<style>
+		table { color: #111; background-color: #eee; width: 400px; border: 2px solid }
+		.group { background-color: #ddd; font-style: italic; width: 100px }
+		tbody { font-weight: bold; text-align: right }
+		.row { color: #222 }
+		.own { color: #333; background-color: inherit }
+	</style>
+	<table border="1">
+		<colgroup class="group" span="2"></colgroup>
+		<colgroup class="group"><col style="color: #444"><col style="background-color: #555" span="2"></colgroup>
+		<tbody><tr class="row"><td>a</td><td>b</td><td>c</td><td>d</td><td>e</td><td class="own">f</td></tr></tbody>
+	</table>`)[0]
+
+	matcher := css.NewMatcher(loadStylesheet(table.MyDocument().Root()))
+	styles := newTableStyles(table, matcher, nil)
+	tbody, _ := table.Search(".//tbody")
+	tr, _ := table.Search(".//tr")
+	cells, _ := table.Search(".//td")
+
+	tests := []struct {
+		cell       int
+		col        int
+		color      string
+		background string
+		italic     bool
+	}{
+		{0, 0, "#222222", "#DDDDDD", true},  // column group over table, row over column
+		{1, 1, "#222222", "#DDDDDD", true},  // span of column group
+		{2, 2, "#222222", "#DDDDDD", true},  // row color over column color
+		{3, 3, "#222222", "#555555", true},  // column over its group
+		{4, 4, "#222222", "#555555", true},  // span of column
+		{5, 4, "#333333", "#555555", true},  // own color, inherited background
+		{4, 5, "#222222", "#EEEEEE", false}, // columns without <col> inherit table
+	}
+
+	for _, test := range tests {
+		declarations := styles.Cell(cells[test.cell], test.col, styles.Own(tbody[0]), styles.Own(tr[0]))
+		style := ExtractStylesDeclarations(declarations, styles.width)
+
+		if style.Color != test.color || style.BackgroundColor != test.background || style.IsItalic != test.italic {
+			t.Errorf("cell of column %d is %s on %s italic %v, want %s on %s italic %v", test.col, style.Color,
+				style.BackgroundColor, style.IsItalic, test.color, test.background, test.italic)
+		}
+
+		// inherited from row group, not inherited table and column width and border
+		if !style.IsBold || style.TextAlign != "right" || style.Width != 0 || style.Borders[0].Width != 1 {
+			t.Errorf("cell of column %d is %+v, want bold right aligned cell without width and table border",
+				test.col, style)
+		}
+	}
+
+	if styles.width != 400 {
+		t.Errorf("table width is %v, want 400", styles.width)
+	}
+}
+
+func TestTableStylesInheritCellOfNestedTable(t *testing.T) {
+	table := parseTables(t, `<table><tr><td>
+		<table style="font-style: italic"><tr><td>a</td></tr></table>
+	</td></tr></table>`)[0]
+	nested := nestedTables(table)[0]
+	matcher := css.NewMatcher(loadStylesheet(table.MyDocument().Root()))
+	inherited := []css.Declaration{{Property: "color", Value: "red"}, {Property: "width", Value: "10px"}}
+	styles := newTableStyles(nested, matcher, inherited)
+	cells, _ := nested.Search(".//td")
+	style := ExtractStylesDeclarations(styles.Cell(cells[0], 0, nil, nil), 0)
+
+	if style.Color != "#FF0000" || !style.IsItalic || style.Width != 0 {
+		t.Errorf("cell of nested table is %+v, want red italic cell without width", style)
+	}
+}
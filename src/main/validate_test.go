@@ -0,0 +1,67 @@
+package main
+
+import (
+	"github.com/icewind666/html-to-excel-renderer/src/jshelpers"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateTemplateExitCode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "validate")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	helpersDir := filepath.Join(dir, "helpers")
+	files := map[string]string{
+		"data.json": `{"title": "Report", "rows": [{"name": "Ann"}]}`,
+		"ok.hbs":    `{{shout title}}{{#each rows}}{{upper name}}{{/each}}`,
+		"bad.hbs":   `{{shout title}}{{#each rows}}{{name}}{{phone}}{{/each}}{{missingHelper title}}`,
+		"helpers/shout.js": `module.exports = {register: function (Handlebars) {
+			Handlebars.registerHelper('shout', function (value) { return value + '!'; });
+		}};`,
+	}
+
+	if err := os.Mkdir(helpersDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	saved := opts
+	defer func() { opts = saved }()
+	opts.HelpersPath = helpersDir
+	opts.HelperTimeout = jshelpers.DefaultTimeout
+
+	tests := []struct {
+		template string
+		data     string
+		want     int
+	}{
+		{"ok.hbs", "data.json", 0},
+		{"ok.hbs", "", 0},
+		{"bad.hbs", "data.json", 1},
+		{"bad.hbs", "", 1}, // missing helper is a problem without data too
+	}
+
+	for _, test := range tests {
+		data := test.data
+
+		if data != "" {
+			data = filepath.Join(dir, data)
+		}
+
+		if code := validateTemplate(filepath.Join(dir, test.template), data); code != test.want {
+			t.Errorf("%s with data %q exits with %d, want %d", test.template, test.data, code, test.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package render
+
+import (
+	"github.com/aymerick/raymond/ast"
+)
+
+// astWalker Visits every node of handlebars template AST. Callbacks are optional
+type astWalker struct {
+	onExpression func(expr *ast.Expression)
+	onPartial    func(partial *ast.PartialStatement)
+}
+
+func (w *astWalker) walk(program *ast.Program) {
+	program.Accept(w)
+}
+
+func (w *astWalker) visitAll(nodes []ast.Node) {
+	for _, node := range nodes {
+		if node != nil {
+			node.Accept(w)
+		}
+	}
+}
+
+func (w *astWalker) VisitProgram(node *ast.Program) interface{} {
+	w.visitAll(node.Body)
+	return nil
+}
+
+func (w *astWalker) VisitMustache(node *ast.MustacheStatement) interface{} {
+	node.Expression.Accept(w)
+	return nil
+}
+
+func (w *astWalker) VisitBlock(node *ast.BlockStatement) interface{} {
+	node.Expression.Accept(w)
+
+	if node.Program != nil {
+		node.Program.Accept(w)
+	}
+
+	if node.Inverse != nil {
+		node.Inverse.Accept(w)
+	}
+
+	return nil
+}
+
+func (w *astWalker) VisitPartial(node *ast.PartialStatement) interface{} {
+	if w.onPartial != nil {
+		w.onPartial(node)
+	}
+
+	w.visitAll(node.Params)
+
+	if node.Hash != nil {
+		node.Hash.Accept(w)
+	}
+
+	return nil
+}
+
+func (w *astWalker) VisitContent(node *ast.ContentStatement) interface{} {
+	return nil
+}
+
+func (w *astWalker) VisitComment(node *ast.CommentStatement) interface{} {
+	return nil
+}
+
+func (w *astWalker) VisitExpression(node *ast.Expression) interface{} {
+	if w.onExpression != nil {
+		w.onExpression(node)
+	}
+
+	node.Path.Accept(w)
+	w.visitAll(node.Params)
+
+	if node.Hash != nil {
+		node.Hash.Accept(w)
+	}
+
+	return nil
+}
+
+func (w *astWalker) VisitSubExpression(node *ast.SubExpression) interface{} {
+	node.Expression.Accept(w)
+	return nil
+}
+
+func (w *astWalker) VisitPath(node *ast.PathExpression) interface{} {
+	return nil
+}
+
+func (w *astWalker) VisitString(node *ast.StringLiteral) interface{} {
+	return nil
+}
+
+func (w *astWalker) VisitBoolean(node *ast.BooleanLiteral) interface{} {
+	return nil
+}
+
+func (w *astWalker) VisitNumber(node *ast.NumberLiteral) interface{} {
+	return nil
+}
+
+func (w *astWalker) VisitHash(node *ast.Hash) interface{} {
+	for _, pair := range node.Pairs {
+		pair.Accept(w)
+	}
+
+	return nil
+}
+
+func (w *astWalker) VisitHashPair(node *ast.HashPair) interface{} {
+	node.Val.Accept(w)
+	return nil
+}
@@ -0,0 +1,273 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Data file formats
+const (
+	DataFormatAuto   = "auto"
+	DataFormatJson   = "json"
+	DataFormatYaml   = "yaml"
+	DataFormatCsv    = "csv"
+	DataFormatNdjson = "ndjson"
+)
+
+// DataOptions Options of data file loading
+type DataOptions struct {
+	Format       string // one of DataFormat* constants. Empty means auto
+	CsvDelimiter rune   // comma by default, tab for .tsv files
+	CsvTypes     bool   // convert csv numbers and booleans from strings, empty values to null
+}
+
+// LoadData Reads data file and converts it into template context.
+// Csv and ndjson files become arrays of objects (one per row or line)
+func LoadData(dataFilename string, options DataOptions) (interface{}, error) {
+	if dataFilename == "" {
+		return map[string]interface{}{}, nil
+	}
+
+	content, err := ReadInput(dataFilename)
+
+	if err != nil {
+		return nil, fmt.Errorf("can't read data file: %w", err)
+	}
+
+	format := options.Format
+
+	if options.CsvDelimiter == 0 && strings.EqualFold(filepath.Ext(dataFilename), ".tsv") {
+		options.CsvDelimiter = '\t' // tab separated values
+	}
+
+	if format == "" || format == DataFormatAuto {
+		format = DetectDataFormat(dataFilename, content)
+	}
+
+	data, err := ParseData(content, format, options)
+
+	if err != nil {
+		return nil, fmt.Errorf("can't parse %s data file %s: %w", format, dataFilename, err)
+	}
+
+	return data, nil
+}
+
+// DetectDataFormat Detects data format by file extension, then by content (standard input has no extension)
+func DetectDataFormat(filename string, content []byte) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return DataFormatJson
+	case ".yaml", ".yml":
+		return DataFormatYaml
+	case ".csv", ".tsv":
+		return DataFormatCsv
+	case ".ndjson", ".jsonl":
+		return DataFormatNdjson
+	}
+
+	trimmed := bytes.TrimSpace(content)
+
+	if len(trimmed) == 0 || trimmed[0] == '[' {
+		return DataFormatJson
+	}
+
+	if trimmed[0] == '{' {
+		// several top level objects on separate lines is ndjson
+		if json.Valid(trimmed) {
+			return DataFormatJson
+		}
+		return DataFormatNdjson
+	}
+
+	if yamlLike(trimmed) {
+		return DataFormatYaml
+	}
+
+	return DataFormatCsv
+}
+
+// yamlLike Checks first meaningful line looks like yaml document start, mapping or list item
+func yamlLike(content []byte) bool {
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		return line == "---" || strings.HasPrefix(line, "- ") ||
+			(strings.Contains(line, ": ") || strings.HasSuffix(line, ":")) && !strings.Contains(line, ",")
+	}
+
+	return false
+}
+
+// ParseData Parses data file content of given format
+func ParseData(content []byte, format string, options DataOptions) (interface{}, error) {
+	switch format {
+	case DataFormatJson:
+		return parseJson(content)
+	case DataFormatYaml:
+		return parseYaml(content)
+	case DataFormatCsv:
+		return parseCsv(content, options)
+	case DataFormatNdjson:
+		return parseNdjson(content)
+	}
+
+	return nil, fmt.Errorf("unknown data format %q", format)
+}
+
+func parseJson(content []byte) (interface{}, error) {
+	var data interface{}
+
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func parseYaml(content []byte) (interface{}, error) {
+	var data interface{}
+
+	if err := yaml.Unmarshal(content, &data); err != nil {
+		return nil, err
+	}
+
+	return normalizeYaml(data), nil
+}
+
+// normalizeYaml Converts yaml maps with interface{} keys into map[string]interface{} used by helpers and engines
+func normalizeYaml(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+
+		for key, item := range v {
+			result[fmt.Sprint(key)] = normalizeYaml(item)
+		}
+
+		return result
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYaml(item)
+		}
+	}
+
+	return value
+}
+
+// parseCsv Parses csv with header row into array of objects
+func parseCsv(content []byte, options DataOptions) (interface{}, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comma = options.CsvDelimiter
+
+	if reader.Comma == 0 {
+		reader.Comma = ','
+	}
+
+	header, err := reader.Read()
+
+	if err == io.EOF {
+		return []interface{}{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff") // excel saves csv with BOM
+	}
+
+	reader.FieldsPerRecord = len(header)
+	rows := make([]interface{}, 0)
+
+	for {
+		record, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(header))
+
+		for i, column := range header {
+			if options.CsvTypes {
+				row[column] = coerceCsvValue(record[i])
+			} else {
+				row[column] = record[i]
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// coerceCsvValue Converts csv value into number, boolean or null when it looks like one
+func coerceCsvValue(value string) interface{} {
+	trimmed := strings.TrimSpace(value)
+
+	switch strings.ToLower(trimmed) {
+	case "":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	// keep values with leading zeros (codes, phone numbers) as strings
+	if len(trimmed) > 1 && trimmed[0] == '0' && trimmed[1] != '.' {
+		return value
+	}
+
+	if f, err := strconv.ParseFloat(trimmed, 64); err == nil && !strings.ContainsAny(trimmed, "xXnN_") {
+		return f
+	}
+
+	return value
+}
+
+// parseNdjson Parses newline delimited json into array of values
+func parseNdjson(content []byte) (interface{}, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), len(content)+1)
+	rows := make([]interface{}, 0)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := bytes.TrimSpace(scanner.Bytes())
+
+		if len(line) == 0 {
+			continue
+		}
+
+		var row interface{}
+
+		if err := json.Unmarshal(line, &row); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, scanner.Err()
+}
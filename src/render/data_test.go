@@ -0,0 +1,127 @@
+package render
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeDataFile Writes data file with given name to temporary directory and returns its path
+func writeDataFile(t *testing.T, name string, content string) string {
+	dir, err := ioutil.TempDir("", "data")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, name)
+
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadData(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		options DataOptions
+		want    interface{}
+	}{
+		{"csv header row", "rows.csv", "\ufeffname,age\nAnn,30\nBob,\n", DataOptions{},
+			[]interface{}{
+				map[string]interface{}{"name": "Ann", "age": "30"},
+				map[string]interface{}{"name": "Bob", "age": ""},
+			}},
+		{"csv quoted fields", "rows.csv", "name,note\n\"Doe, John\",\"said \"\"hi\"\"\ntwice\"\n", DataOptions{},
+			[]interface{}{map[string]interface{}{"name": "Doe, John", "note": "said \"hi\"\ntwice"}}},
+		{"csv delimiter", "rows.csv", "name;age\nAnn;30\n", DataOptions{CsvDelimiter: ';'},
+			[]interface{}{map[string]interface{}{"name": "Ann", "age": "30"}}},
+		{"csv types", "rows.csv", "code,age,ok,empty\n007,30.5,true,\n", DataOptions{CsvTypes: true},
+			[]interface{}{map[string]interface{}{"code": "007", "age": 30.5, "ok": true, "empty": nil}}},
+		{"csv header only", "rows.csv", "name,age\n", DataOptions{}, []interface{}{}},
+		{"tsv", "rows.tsv", "name\tnote\nAnn\tfirst, second\n", DataOptions{},
+			[]interface{}{map[string]interface{}{"name": "Ann", "note": "first, second"}}},
+		{"tsv with delimiter", "rows.TSV", "name,note\nAnn,first\n", DataOptions{CsvDelimiter: ','},
+			[]interface{}{map[string]interface{}{"name": "Ann", "note": "first"}}},
+		{"yaml map keys", "data.yaml", "total: 3\nrows:\n  - 1: one\n    true: ok\n  - name: two\n", DataOptions{},
+			map[string]interface{}{"total": 3, "rows": []interface{}{
+				map[string]interface{}{"1": "one", "true": "ok"},
+				map[string]interface{}{"name": "two"},
+			}}},
+		{"ndjson blank lines", "rows.ndjson", "{\"a\": 1}\n\n  \n{\"a\": 2}\n", DataOptions{},
+			[]interface{}{map[string]interface{}{"a": 1.0}, map[string]interface{}{"a": 2.0}}},
+		{"ndjson detected by content", "-.txt", "{\"a\": 1}\n{\"a\": 2}", DataOptions{},
+			[]interface{}{map[string]interface{}{"a": 1.0}, map[string]interface{}{"a": 2.0}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := LoadData(writeDataFile(t, test.file, test.content), test.options)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(data, test.want) {
+				t.Errorf("data is %#v, want %#v", data, test.want)
+			}
+		})
+	}
+}
+
+func TestLoadDataErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		message string
+	}{
+		{"ndjson bad line", "rows.ndjson", "{\"a\": 1}\n\n{\"a\": }\n", "can't parse ndjson data file"},
+		{"ndjson bad line number", "rows.ndjson", "{\"a\": 1}\n\n{\"a\": }\n", "line 3:"},
+		{"csv wrong number of fields", "rows.csv", "a,b\n1,2,3\n", "wrong number of fields"},
+		{"yaml syntax", "data.yml", "a: [1,\n", "can't parse yaml data file"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := LoadData(writeDataFile(t, test.file, test.content), DataOptions{})
+
+			if err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Errorf("error is %v, want error containing %q", err, test.message)
+			}
+		})
+	}
+}
+
+func TestDetectDataFormat(t *testing.T) {
+	tests := []struct {
+		filename string
+		content  string
+		want     string
+	}{
+		{"data.json", "", DataFormatJson},
+		{"data.yml", "", DataFormatYaml},
+		{"data.tsv", "", DataFormatCsv},
+		{"data.jsonl", "", DataFormatNdjson},
+		{"-", "[1, 2]", DataFormatJson},
+		{"-", "{\"a\": 1}", DataFormatJson},
+		{"-", "{\"a\": 1}\n{\"a\": 2}", DataFormatNdjson},
+		{"-", "# rows\nname: Ann", DataFormatYaml},
+		{"-", "---\n- 1", DataFormatYaml},
+		{"-", "name,note\nAnn,a: b", DataFormatCsv},
+	}
+
+	for _, test := range tests {
+		if format := DetectDataFormat(test.filename, []byte(test.content)); format != test.want {
+			t.Errorf("format of %s %q is %s, want %s", test.filename, test.content, format, test.want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package render
+
+import (
+	"fmt"
+)
+
+// Template engine names
+const (
+	EngineHandlebars = "handlebars"
+	EngineGoTemplate = "gotemplate"
+	EngineNone       = "none"
+)
+
+// Engine Template engine rendering template source with data into html.
+// Name is template file name, used in error messages
+type Engine interface {
+	Render(name string, source string, data interface{}) (string, error)
+}
+
+// NoneEngine Passes html source through untouched
+type NoneEngine struct{}
+
+// Render Returns source as is
+func (e *NoneEngine) Render(name string, source string, data interface{}) (string, error) {
+	return source, nil
+}
+
+// RenderFile Renders template file with data using given engine
+func RenderFile(engine Engine, templateFilename string, data interface{}) (string, error) {
+	source, err := ReadInput(templateFilename)
+
+	if err != nil {
+		return "", fmt.Errorf("can't read template file: %w", err)
+	}
+
+	return engine.Render(templateFilename, string(source), data)
+}
@@ -0,0 +1,87 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// raymond reports node positions as byte offsets ("Pos:123") and parse errors as "Parse error on line N"
+var posRegexp = regexp.MustCompile(`Pos: ?(\d+)`)
+var lineRegexp = regexp.MustCompile(`^Parse error on line (\d+):`)
+var lexerErrorRegexp = regexp.MustCompile(`Token: Error\{"(.*)"\}`)
+
+// TemplateError Template rendering error with position in template source
+type TemplateError struct {
+	Filename string
+	Line     int
+	Column   int
+	Message  string
+}
+
+func (e *TemplateError) Error() string {
+	if e.Line <= 0 {
+		return fmt.Sprintf("%s: %s", e.Filename, e.Message)
+	}
+
+	if e.Column <= 0 {
+		return fmt.Sprintf("%s:%d: %s", e.Filename, e.Line, e.Message)
+	}
+
+	return fmt.Sprintf("%s:%d:%d: %s", e.Filename, e.Line, e.Column, e.Message)
+}
+
+// HelperPanicError Runtime panic of Go helper (nil map, index out of range...) returned as rendering error
+type HelperPanicError struct {
+	Helper string
+	Value  interface{}
+}
+
+func (e *HelperPanicError) Error() string {
+	return fmt.Sprintf("helper %s failed: %v", e.Helper, e.Value)
+}
+
+// newTemplateError Converts raymond error into TemplateError. Position is taken from the
+// innermost node mentioned in the error message
+func newTemplateError(filename string, source string, err error) *TemplateError {
+	msg := err.Error()
+	result := &TemplateError{Filename: filename}
+
+	if m := lineRegexp.FindStringSubmatch(msg); m != nil {
+		result.Line, _ = strconv.Atoi(m[1])
+		msg = strings.TrimSpace(msg[len(m[0]):])
+	}
+
+	msg = strings.TrimPrefix(msg, "Evaluation error: ")
+
+	if all := posRegexp.FindAllStringSubmatch(msg, -1); all != nil {
+		pos, _ := strconv.Atoi(all[len(all)-1][1])
+		result.Line, result.Column = positionToLineColumn(source, pos)
+	}
+
+	// first line holds the message, the rest is raymond's node dump
+	if i := strings.Index(msg, "\n"); i >= 0 {
+		if m := lexerErrorRegexp.FindStringSubmatch(msg); m != nil {
+			msg = msg[:i] + ": " + m[1]
+		} else {
+			msg = msg[:i]
+		}
+	}
+
+	result.Message = msg
+	return result
+}
+
+// positionToLineColumn Converts byte offset to 1-based line and column
+func positionToLineColumn(source string, pos int) (int, int) {
+	if pos > len(source) {
+		pos = len(source)
+	}
+
+	before := source[:pos]
+	line := strings.Count(before, "\n") + 1
+	column := pos - strings.LastIndex(before, "\n")
+
+	return line, column
+}
@@ -0,0 +1,84 @@
+package render
+
+import (
+	"path/filepath"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// emptyFunc Name of function appended to printed pipelines, so missing values are printed as empty strings
+const emptyFunc = "_empty"
+
+// GoTemplateEngine Renders Go text/template templates
+type GoTemplateEngine struct {
+	Funcs template.FuncMap
+}
+
+// Render Renders text/template source with data as dot. Errors already hold template line and column.
+// Missing values are printed as empty strings like Handlebars does, not as <no value>
+func (e *GoTemplateEngine) Render(name string, source string, data interface{}) (string, error) {
+	tpl, err := template.New(filepath.Base(name)).
+		Option("missingkey=zero").
+		Funcs(template.FuncMap{emptyFunc: emptyIfNil}).
+		Funcs(e.Funcs).
+		Parse(source)
+
+	if err != nil {
+		return "", err
+	}
+
+	for _, defined := range tpl.Templates() {
+		if defined.Tree != nil {
+			printMissingAsEmpty(defined.Tree, defined.Tree.Root)
+		}
+	}
+
+	var result strings.Builder
+
+	if err := tpl.Execute(&result, data); err != nil {
+		return "", err
+	}
+
+	return result.String(), nil
+}
+
+// printMissingAsEmpty Appends empty function to pipelines printed by actions of node and its branches
+func printMissingAsEmpty(tree *parse.Tree, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, child := range n.Nodes {
+			printMissingAsEmpty(tree, child)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) > 0 {
+			return // variable declarations and assignments print nothing
+		}
+
+		identifier := parse.NewIdentifier(emptyFunc).SetTree(tree).SetPos(n.Pos)
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{NodeType: parse.NodeCommand, Pos: n.Pos,
+			Args: []parse.Node{identifier}})
+	case *parse.IfNode:
+		printMissingAsEmpty(tree, n.List)
+		printMissingAsEmpty(tree, n.ElseList)
+	case *parse.RangeNode:
+		printMissingAsEmpty(tree, n.List)
+		printMissingAsEmpty(tree, n.ElseList)
+	case *parse.WithNode:
+		printMissingAsEmpty(tree, n.List)
+		printMissingAsEmpty(tree, n.ElseList)
+	}
+}
+
+// emptyIfNil Returns empty string for nil (missing value), other values as is
+func emptyIfNil(value interface{}) interface{} {
+	if value == nil {
+		return ""
+	}
+
+	return value
+}
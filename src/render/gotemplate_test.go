@@ -0,0 +1,49 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGoTemplatePrintsMissingValuesEmpty(t *testing.T) {
+	engine := &GoTemplateEngine{}
+	data := map[string]interface{}{
+		"name": "Ann",
+		"rows": []interface{}{map[string]interface{}{"a": 1}, map[string]interface{}{}},
+		"org":  map[string]interface{}{},
+	}
+
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{`<td>{{ .missing }}</td>`, "<td></td>"},
+		{`<td>{{ .name }}{{ .org.name }}</td>`, "<td>Ann</td>"},
+		{`{{ range .rows }}<td>{{ .a }}</td>{{ end }}`, "<td>1</td><td></td>"},
+		{`{{ if .name }}{{ .missing }}{{ else }}no{{ end }}|{{ with .org }}{{ .name }}{{ end }}`, "|"},
+		{`{{ $value := .missing }}[{{ $value }}]`, "[]"},
+		{`{{ define "cell" }}<td>{{ .missing }}</td>{{ end }}{{ template "cell" . }}`, "<td></td>"},
+		{`{{ .name | printf "%s!" }} {{ 0 }} {{ false }}`, "Ann! 0 false"},
+	}
+
+	for _, test := range tests {
+		result, err := engine.Render("report.tmpl", test.source, data)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != test.want {
+			t.Errorf("%s = %q, want %q", test.source, result, test.want)
+		}
+	}
+}
+
+func TestGoTemplateErrorsHoldPosition(t *testing.T) {
+	engine := &GoTemplateEngine{}
+	_, err := engine.Render("dir/report.tmpl", "<td>\n{{ .name.first }}</td>", map[string]interface{}{"name": "Ann"})
+
+	if err == nil || !strings.Contains(err.Error(), "report.tmpl:2:") {
+		t.Errorf("error is %v, want error at line 2 of report.tmpl", err)
+	}
+}
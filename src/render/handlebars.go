@@ -0,0 +1,107 @@
+package render
+
+import (
+	"github.com/aymerick/raymond"
+	"reflect"
+	"runtime"
+)
+
+// HandlebarsEngine Renders handlebars.js templates in-process
+type HandlebarsEngine struct {
+	Helpers  map[string]interface{}
+	Partials map[string]string // partial name -> partial source
+}
+
+// Render Renders handlebars.js template source with data as context
+func (e *HandlebarsEngine) Render(name string, source string, data interface{}) (string, error) {
+	return e.render(name, source, data, nil)
+}
+
+// render Renders template source with given context and private data (used by layouts)
+func (e *HandlebarsEngine) render(name string, source string, ctx interface{}, privData *raymond.DataFrame) (string, error) {
+	sources := e.sources(name, source)
+	calls, err := findVariadicCalls(sources, e.Helpers)
+
+	if err != nil {
+		return "", err
+	}
+
+	bound := calls.bind(name, source)
+	tpl, err := raymond.Parse(bound.text)
+
+	if err != nil {
+		return "", bound.templateError(name, err)
+	}
+
+	for partialName, partialSource := range e.Partials {
+		tpl.RegisterPartial(partialName, calls.bind(partialName, partialSource).text)
+	}
+
+	for helperName, helper := range e.Helpers {
+		if _, ok := helper.(VariadicHelper); !ok {
+			tpl.RegisterHelper(helperName, recoverHelper(helperName, helper))
+		}
+	}
+
+	calls.register(tpl, e.Helpers)
+
+	for helperName, helper := range e.layoutHelpers() {
+		if _, exists := e.Helpers[helperName]; !exists {
+			tpl.RegisterHelper(helperName, helper)
+		}
+	}
+
+	result, err := tpl.ExecWith(ctx, privData)
+
+	if templateErr, ok := err.(*TemplateError); ok {
+		return "", templateErr // error in layout rendered by extend helper
+	}
+
+	if err != nil {
+		return "", bound.templateError(name, err)
+	}
+
+	return result, nil
+}
+
+// sources Returns template and partials sources by file name
+func (e *HandlebarsEngine) sources(name string, source string) map[string]string {
+	result := make(map[string]string, len(e.Partials)+1)
+
+	for partialName, partialSource := range e.Partials {
+		result[partialName] = partialSource
+	}
+
+	result[name] = source
+	return result
+}
+
+// recoverHelper Wraps helper function to turn its runtime panics and panics with non error values into
+// HelperPanicError. raymond returns panics with errors as rendering errors, but re-panics the others
+func recoverHelper(name string, helper interface{}) interface{} {
+	helperValue := reflect.ValueOf(helper)
+
+	if helperValue.Kind() != reflect.Func {
+		return helper // raymond reports invalid helper itself
+	}
+
+	return reflect.MakeFunc(helperValue.Type(), func(args []reflect.Value) (results []reflect.Value) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				_, isRuntime := recovered.(runtime.Error)
+
+				if _, isError := recovered.(error); isRuntime || !isError {
+					panic(&HelperPanicError{Helper: name, Value: recovered})
+				}
+
+				panic(recovered)
+			}
+		}()
+
+		if helperValue.Type().IsVariadic() {
+			return helperValue.CallSlice(args)
+		}
+
+		return helperValue.Call(args)
+	}).Interface()
+}
@@ -0,0 +1,55 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHandlebarsHelperPanicIsTemplateError(t *testing.T) {
+	tests := []struct {
+		name    string
+		helper  interface{}
+		message string
+	}{
+		{"nil map", func(key string) string {
+			var values map[string]int
+			values[key] = 1
+			return key
+		}, "helper broken failed: assignment to entry in nil map"},
+		{"index out of range", func(key string) string {
+			return strings.Split(key, ",")[3]
+		}, "helper broken failed: runtime error: index out of range [3] with length 1"},
+		{"panic with string", func(key string) string {
+			panic("not an error")
+		}, "helper broken failed: not an error"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine := &HandlebarsEngine{Helpers: map[string]interface{}{"broken": test.helper}}
+			_, err := engine.Render("report.hbs", `<td>{{broken "a"}}</td>`, nil)
+
+			templateErr, ok := err.(*TemplateError)
+
+			if !ok {
+				t.Fatalf("expected TemplateError, got %T %v", err, err)
+			}
+
+			if templateErr.Filename != "report.hbs" || !strings.Contains(templateErr.Message, test.message) {
+				t.Errorf("unexpected error %q", templateErr.Error())
+			}
+		})
+	}
+}
+
+func TestHandlebarsHelperErrorPanicIsKept(t *testing.T) {
+	engine := &HandlebarsEngine{Helpers: map[string]interface{}{"fail": func(key string) string {
+		panic(&TemplateError{Filename: "layout.hbs", Line: 2, Message: "broken layout"})
+	}}}
+
+	_, err := engine.Render("report.hbs", `{{fail "a"}}`, nil)
+
+	if err == nil || err.Error() != "layout.hbs:2: broken layout" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+package render
+
+import (
+	"github.com/icewind666/html-to-excel-renderer/src/types"
+	"io/ioutil"
+	"os"
+)
+
+// ReadInput Reads input file contents. "-" reads standard input
+func ReadInput(filename string) ([]byte, error) {
+	if filename == types.StdioFilename {
+		return ioutil.ReadAll(os.Stdin)
+	}
+
+	return ioutil.ReadFile(filename)
+}
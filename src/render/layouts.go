@@ -0,0 +1,121 @@
+package render
+
+import (
+	"fmt"
+	"github.com/aymerick/raymond"
+)
+
+// layoutBlocksKey Private data key holding content of templates extending a layout
+const layoutBlocksKey = "layoutBlocks"
+
+// Content modes of {{#content}} helper
+const (
+	contentModeReplace = "replace"
+	contentModeAppend  = "append"
+	contentModePrepend = "prepend"
+)
+
+// blockAction Content provided for layout block
+type blockAction struct {
+	mode    string
+	content string
+}
+
+// layoutBlocks Content actions by block name. Actions are applied in order, so content of
+// the most specific template comes last
+type layoutBlocks map[string][]blockAction
+
+// layoutHelpers Returns layout helpers:
+//
+// layouts/base.hbs: <table>{{#block "header"}}<tr><td>Default</td></tr>{{/block}}</table>
+//
+// report.hbs: {{#extend "layouts/base"}}{{#content "header" mode="append"}}...{{/content}}{{/extend}}
+func (e *HandlebarsEngine) layoutHelpers() map[string]interface{} {
+	return map[string]interface{}{
+		"extend":  e.extendHelper,
+		"block":   blockHelper,
+		"content": contentHelper,
+	}
+}
+
+// extendHelper Renders layout partial with content blocks defined inside extend block.
+// Anything except content blocks inside extend block is dropped
+func (e *HandlebarsEngine) extendHelper(name string, options *raymond.Options) raymond.SafeString {
+	layout, ok := e.Partials[name]
+
+	if !ok {
+		panic(fmt.Errorf("layout %s is not found in partials", name))
+	}
+
+	collected := make(layoutBlocks)
+	frame := options.NewDataFrame()
+	frame.Set(layoutBlocksKey, collected)
+	options.FnData(frame)
+
+	// layout extending another layout: content of the template extending it wins
+	if inherited, ok := options.Data(layoutBlocksKey).(layoutBlocks); ok {
+		for blockName, actions := range inherited {
+			collected[blockName] = append(collected[blockName], actions...)
+		}
+	}
+
+	layoutFrame := raymond.NewDataFrame()
+	layoutFrame.Set(layoutBlocksKey, collected)
+	result, err := e.render(name, layout, options.Ctx(), layoutFrame)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return raymond.SafeString(result)
+}
+
+// contentHelper Stores content for layout block. Mode hash argument is replace (default), append or prepend
+func contentHelper(name string, options *raymond.Options) string {
+	blocks, ok := options.Data(layoutBlocksKey).(layoutBlocks)
+
+	if !ok {
+		panic(fmt.Errorf("content %s is used outside of extend block", name))
+	}
+
+	mode := options.HashStr("mode")
+
+	switch mode {
+	case "":
+		mode = contentModeReplace
+	case contentModeReplace, contentModeAppend, contentModePrepend:
+	default:
+		panic(fmt.Errorf("unknown content mode %q, expected replace, append or prepend", mode))
+	}
+
+	blocks[name] = append(blocks[name], blockAction{mode: mode, content: options.Fn()})
+	return ""
+}
+
+// blockHelper Renders layout block: its default content changed by content of extending templates.
+// Must be used as block ({{#block "name"}}{{/block}}) even without default content
+func blockHelper(name string, options *raymond.Options) raymond.SafeString {
+	guardKey := "layoutBlock:" + name
+
+	if options.Data(guardKey) != nil {
+		return "" // raymond evaluates enclosing block when helper is used as {{block "name"}}
+	}
+
+	frame := options.NewDataFrame()
+	frame.Set(guardKey, true)
+	result := options.FnData(frame)
+	blocks, _ := options.Data(layoutBlocksKey).(layoutBlocks)
+
+	for _, action := range blocks[name] {
+		switch action.mode {
+		case contentModeAppend:
+			result += action.content
+		case contentModePrepend:
+			result = action.content + result
+		default:
+			result = action.content
+		}
+	}
+
+	return raymond.SafeString(result)
+}
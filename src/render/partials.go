@@ -0,0 +1,60 @@
+package render
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// partialExtensions Extensions of files registered as partials
+var partialExtensions = map[string]bool{
+	".hbs":        true,
+	".handlebars": true,
+}
+
+// LoadPartials Reads handlebars partials from directory and its subdirectories. Partial is named by file path
+// relative to directory without extension: header.hbs is {{> header}}, layouts/base.hbs is {{> layouts/base}}
+func LoadPartials(dir string) (map[string]string, error) {
+	partials := make(map[string]string)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		ext := filepath.Ext(path)
+
+		if info.IsDir() || !partialExtensions[ext] {
+			return nil
+		}
+
+		relative, err := filepath.Rel(dir, path)
+
+		if err != nil {
+			return err
+		}
+
+		source, err := ioutil.ReadFile(path)
+
+		if err != nil {
+			return err
+		}
+
+		name := filepath.ToSlash(strings.TrimSuffix(relative, ext))
+
+		if _, exists := partials[name]; exists {
+			return fmt.Errorf("partial %s is defined by several files (%s)", name, path)
+		}
+
+		partials[name] = string(source)
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("can't load partials: %w", err)
+	}
+
+	return partials, nil
+}
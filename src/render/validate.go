@@ -0,0 +1,537 @@
+package render
+
+import (
+	"fmt"
+	"github.com/aymerick/raymond/ast"
+	"github.com/aymerick/raymond/parser"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// raymondHelpers Helpers built into raymond
+var raymondHelpers = []string{"if", "unless", "with", "each", "log", "lookup", "equal"}
+
+// ValidationProblem Problem found in template. Position is empty for unused data keys
+type ValidationProblem struct {
+	Filename string
+	Line     int
+	Column   int
+	Message  string
+}
+
+func (p ValidationProblem) String() string {
+	if p.Filename == "" {
+		return p.Message
+	}
+
+	return fmt.Sprintf("%s:%d:%d: %s", p.Filename, p.Line, p.Column, p.Message)
+}
+
+// ValidationReport Result of handlebars template validation against data.
+// Data paths are normalized: array items are marked with [] (rows[].name)
+type ValidationReport struct {
+	Paths    []string // data paths referenced by template
+	Helpers  []string // helpers referenced by template
+	Problems []ValidationProblem
+}
+
+// HasProblems Checks report has missing helpers, partials, data paths or unused data keys
+func (r *ValidationReport) HasProblems() bool {
+	return len(r.Problems) > 0
+}
+
+// validationScope Template context: possible data values (several for array items) and their data path
+type validationScope struct {
+	values      []interface{}
+	path        string
+	parent      *validationScope
+	blockParams map[string]*validationScope
+}
+
+// validator Walks template AST resolving paths against data
+type validator struct {
+	helpers  map[string]bool
+	partials map[string]string
+	root     *validationScope
+
+	filename     string
+	source       string
+	partialStack []string
+
+	paths       map[string]bool
+	usedHelpers map[string]bool
+	used        map[string]bool // data paths used by template
+	usedSubtree map[string]bool // data paths passed to helpers or printed as a whole, all nested keys are used
+	problems    []ValidationProblem
+}
+
+// Validate Checks handlebars template against helpers, partials and data. Data may be nil,
+// then data paths are listed but not checked
+func (e *HandlebarsEngine) Validate(name string, source string, data interface{}) (*ValidationReport, error) {
+	v := &validator{
+		helpers:     make(map[string]bool),
+		partials:    e.Partials,
+		paths:       make(map[string]bool),
+		usedHelpers: make(map[string]bool),
+		used:        make(map[string]bool),
+		usedSubtree: make(map[string]bool),
+	}
+
+	for helperName := range e.Helpers {
+		v.helpers[helperName] = true
+	}
+
+	for helperName := range e.layoutHelpers() {
+		v.helpers[helperName] = true
+	}
+
+	for _, helperName := range raymondHelpers {
+		v.helpers[helperName] = true
+	}
+
+	if data != nil {
+		v.root = &validationScope{values: []interface{}{data}}
+	} else {
+		v.root = &validationScope{}
+	}
+
+	if err := v.walkFile(name, source, v.root); err != nil {
+		return nil, err
+	}
+
+	if data != nil {
+		v.reportUnused([]interface{}{data}, "")
+	}
+
+	return &ValidationReport{
+		Paths:    sortedKeys(v.paths),
+		Helpers:  sortedKeys(v.usedHelpers),
+		Problems: v.problems,
+	}, nil
+}
+
+// walkFile Parses and walks template or partial source
+func (v *validator) walkFile(filename string, source string, scope *validationScope) error {
+	program, err := parser.Parse(source)
+
+	if err != nil {
+		return newTemplateError(filename, source, err)
+	}
+
+	prevFilename, prevSource := v.filename, v.source
+	v.filename, v.source = filename, source
+	v.program(program, scope)
+	v.filename, v.source = prevFilename, prevSource
+
+	return nil
+}
+
+func (v *validator) problem(node ast.Node, format string, args ...interface{}) {
+	line, column := positionToLineColumn(v.source, node.Location().Pos)
+	v.problems = append(v.problems, ValidationProblem{
+		Filename: v.filename,
+		Line:     line,
+		Column:   column,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) program(program *ast.Program, scope *validationScope) {
+	if program == nil {
+		return
+	}
+
+	for _, node := range program.Body {
+		switch n := node.(type) {
+		case *ast.MustacheStatement:
+			v.expression(n.Expression, scope)
+		case *ast.BlockStatement:
+			v.block(n, scope)
+		case *ast.PartialStatement:
+			v.partial(n, scope)
+		}
+	}
+}
+
+// block Walks block statement. each and with change context, other helpers keep it
+func (v *validator) block(block *ast.BlockStatement, scope *validationScope) {
+	expr := block.Expression
+	name := expr.HelperName()
+
+	if name == "" || (!v.helpers[name] && len(expr.Params) == 0 && expr.Hash == nil) {
+		// mustache section {{#rows}}...{{/rows}}: iterates arrays, changes context to objects
+		values, path, _ := v.resolveNode(expr.Path, scope, false)
+
+		if isArrayValue(values) {
+			values, path = v.items(values, path)
+		}
+
+		v.program(block.Program, v.childScope(scope, values, path, block.Program))
+		v.program(block.Inverse, scope)
+		return
+	}
+
+	v.useHelper(expr, name)
+
+	switch name {
+	case "each", "with":
+		if len(expr.Params) == 0 {
+			break
+		}
+
+		values, path, _ := v.resolveNode(expr.Params[0], scope, false)
+
+		if name == "each" {
+			values, path = v.items(values, path)
+		}
+
+		v.program(block.Program, v.childScope(scope, values, path, block.Program))
+
+		v.walkHash(expr, scope)
+		v.program(block.Inverse, scope)
+		return
+	case "extend":
+		v.params(expr, scope)
+
+		if len(expr.Params) > 0 {
+			if layout, ok := expr.Params[0].(*ast.StringLiteral); ok {
+				v.partialByName(block, layout.Value, scope)
+			}
+		}
+	case "if", "unless":
+		for _, param := range expr.Params {
+			v.resolveNode(param, scope, false)
+		}
+	default:
+		v.params(expr, scope)
+	}
+
+	v.program(block.Program, scope)
+	v.program(block.Inverse, scope)
+}
+
+// childScope Creates context scope of block. First block param refers to the new context
+func (v *validator) childScope(scope *validationScope, values []interface{}, path string, program *ast.Program) *validationScope {
+	child := &validationScope{values: values, path: path, parent: scope}
+
+	if program != nil && len(program.BlockParams) > 0 {
+		child.blockParams = map[string]*validationScope{
+			program.BlockParams[0]: {values: values, path: path, parent: scope},
+		}
+	}
+
+	return child
+}
+
+// isArrayValue Checks any of possible values is array
+func isArrayValue(values []interface{}) bool {
+	for _, value := range values {
+		if _, ok := value.([]interface{}); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// items Returns array items or object values iterated by each
+func (v *validator) items(values []interface{}, path string) ([]interface{}, string) {
+	var items []interface{}
+
+	for _, value := range values {
+		switch collection := value.(type) {
+		case []interface{}:
+			items = append(items, collection...)
+		case map[string]interface{}:
+			v.usedSubtree[path] = true // keys of iterated object are data, not schema
+
+			for _, item := range collection {
+				items = append(items, item)
+			}
+		}
+	}
+
+	return items, path + "[]"
+}
+
+// expression Walks mustache or subexpression: either helper call or data path
+func (v *validator) expression(expr *ast.Expression, scope *validationScope) {
+	name := expr.HelperName()
+
+	if name != "" && (v.helpers[name] || len(expr.Params) > 0 || expr.Hash != nil) {
+		v.useHelper(expr, name)
+		v.params(expr, scope)
+		return
+	}
+
+	v.resolveNode(expr.Path, scope, true)
+}
+
+func (v *validator) useHelper(expr *ast.Expression, name string) {
+	v.usedHelpers[name] = true
+
+	if !v.helpers[name] {
+		v.problem(expr, "missing helper %s", name)
+	}
+}
+
+// params Walks helper arguments. Helper may use any nested key of data passed to it
+func (v *validator) params(expr *ast.Expression, scope *validationScope) {
+	for _, param := range expr.Params {
+		v.resolveNode(param, scope, true)
+	}
+
+	v.walkHash(expr, scope)
+}
+
+func (v *validator) walkHash(expr *ast.Expression, scope *validationScope) {
+	if expr.Hash == nil {
+		return
+	}
+
+	for _, pair := range expr.Hash.Pairs {
+		v.resolveNode(pair.Val, scope, true)
+	}
+}
+
+// partial Walks partial with current context or context given as partial argument
+func (v *validator) partial(partial *ast.PartialStatement, scope *validationScope) {
+	partialScope := scope
+
+	if len(partial.Params) > 0 {
+		values, path, _ := v.resolveNode(partial.Params[0], scope, false)
+		partialScope = &validationScope{values: values, path: path, parent: scope}
+	}
+
+	if partial.Hash != nil {
+		for _, pair := range partial.Hash.Pairs {
+			v.resolveNode(pair.Val, scope, true)
+		}
+	}
+
+	if name, ok := partial.Name.(*ast.PathExpression); ok {
+		v.partialByName(partial, name.Original, partialScope)
+	}
+}
+
+func (v *validator) partialByName(node ast.Node, name string, scope *validationScope) {
+	source, ok := v.partials[name]
+
+	if !ok {
+		v.problem(node, "missing partial %s", name)
+		return
+	}
+
+	for _, walked := range v.partialStack {
+		if walked == name {
+			return // recursive partial
+		}
+	}
+
+	v.partialStack = append(v.partialStack, name)
+
+	if err := v.walkFile(name, source, scope); err != nil {
+		v.problems = append(v.problems, ValidationProblem{Message: err.Error()})
+	}
+
+	v.partialStack = v.partialStack[:len(v.partialStack)-1]
+}
+
+// resolveNode Resolves data path of argument node. Returns false when values are unknown
+// (literals, private data like @index, missing paths)
+func (v *validator) resolveNode(node ast.Node, scope *validationScope, subtree bool) ([]interface{}, string, bool) {
+	switch n := node.(type) {
+	case *ast.SubExpression:
+		v.expression(n.Expression, scope)
+	case *ast.Expression:
+		v.expression(n, scope)
+	case *ast.PathExpression:
+		return v.resolvePath(n, scope, subtree)
+	}
+
+	return nil, "", false
+}
+
+// resolvePath Resolves path against possible context values, marking data keys as used
+func (v *validator) resolvePath(path *ast.PathExpression, scope *validationScope, subtree bool) ([]interface{}, string, bool) {
+	parts := path.Parts
+
+	if path.Data {
+		if len(parts) == 0 || parts[0] != "root" {
+			return nil, "", false // @index, @key, @first, @last
+		}
+
+		scope = v.root
+		parts = parts[1:]
+	} else {
+		for i := 0; i < path.Depth && scope.parent != nil; i++ {
+			scope = scope.parent
+		}
+
+		if !path.Scoped && len(parts) > 0 {
+			if param := findBlockParam(scope, parts[0]); param != nil {
+				scope = param
+				parts = parts[1:]
+			}
+		}
+	}
+
+	values, keyPath := scope.values, scope.path
+	known := values != nil // false without data or inside unknown context
+
+	for _, part := range parts {
+		keyPath = joinPath(keyPath, part)
+
+		if !known {
+			continue
+		}
+
+		next, found, checked := lookupPart(values, part)
+
+		if checked && !found {
+			v.paths[displayPath(keyPath)] = true
+			v.problem(path, "data path %s is absent in data", keyPath)
+			return nil, keyPath, false
+		}
+
+		v.used[keyPath] = true
+		values, known = next, checked
+	}
+
+	v.paths[displayPath(keyPath)] = true
+
+	if subtree {
+		v.usedSubtree[keyPath] = true
+	}
+
+	if !known {
+		return nil, keyPath, false
+	}
+
+	return values, keyPath, true
+}
+
+// findBlockParam Finds block param scope by name in enclosing scopes
+func findBlockParam(scope *validationScope, name string) *validationScope {
+	for ; scope != nil; scope = scope.parent {
+		if param, ok := scope.blockParams[name]; ok {
+			return param
+		}
+	}
+
+	return nil
+}
+
+// lookupPart Returns values of key in possible values. Checked is false when there are no objects
+// to look key up in (nulls, scalars)
+func lookupPart(values []interface{}, part string) (next []interface{}, found bool, checked bool) {
+	for _, value := range values {
+		switch obj := value.(type) {
+		case map[string]interface{}:
+			checked = true
+
+			if item, ok := obj[part]; ok {
+				found = true
+
+				if item != nil {
+					next = append(next, item)
+				}
+			}
+		case []interface{}:
+			checked = true
+
+			if part == "length" {
+				found = true
+				next = append(next, float64(len(obj)))
+			} else if index, err := strconv.Atoi(part); err == nil {
+				found = true
+
+				if index >= 0 && index < len(obj) && obj[index] != nil {
+					next = append(next, obj[index])
+				}
+			}
+		}
+	}
+
+	if found && next == nil {
+		checked = false // only nulls, nested keys can't be checked
+	}
+
+	return next, found, checked
+}
+
+// reportUnused Reports data keys not used by template. Only the topmost unused key of a subtree is reported
+func (v *validator) reportUnused(values []interface{}, path string) {
+	if v.usedSubtree[path] {
+		return
+	}
+
+	keys := make(map[string][]interface{}) // values of key in all objects
+	var names []string
+	var items []interface{}
+
+	for _, value := range values {
+		switch obj := value.(type) {
+		case map[string]interface{}:
+			for key, item := range obj {
+				if _, seen := keys[key]; !seen {
+					names = append(names, key)
+				}
+
+				keys[key] = append(keys[key], item)
+			}
+		case []interface{}:
+			items = append(items, obj...)
+		}
+	}
+
+	if len(items) > 0 {
+		v.reportUnused(items, path+"[]")
+	}
+
+	sort.Strings(names)
+
+	for _, key := range names {
+		keyPath := joinPath(path, key)
+
+		if !v.used[keyPath] {
+			v.problems = append(v.problems, ValidationProblem{Message: fmt.Sprintf("unused data key %s", keyPath)})
+			continue
+		}
+
+		v.reportUnused(keys[key], keyPath)
+	}
+}
+
+func joinPath(path string, part string) string {
+	if path == "" {
+		return part
+	}
+
+	if _, err := strconv.Atoi(part); err == nil && !strings.HasSuffix(path, "[]") {
+		return path + "[]" // rows.0.name is rows[].name
+	}
+
+	return path + "." + part
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "this"
+	}
+
+	return path
+}
+
+// sortedKeys Returns sorted keys of set
+func sortedKeys(m map[string]bool) []string {
+	var result []string
+
+	for key := range m {
+		result = append(result, key)
+	}
+
+	sort.Strings(result)
+	return result
+}
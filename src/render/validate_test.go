@@ -0,0 +1,126 @@
+package render
+
+import (
+	"github.com/aymerick/raymond"
+	"reflect"
+	"testing"
+)
+
+func TestHandlebarsValidate(t *testing.T) {
+	data := map[string]interface{}{
+		"title": "Report",
+		"org":   map[string]interface{}{"name": "ООО", "inn": "123"},
+		"rows": []interface{}{
+			map[string]interface{}{"name": "Ann", "age": 30.0},
+			map[string]interface{}{"name": "Bob", "note": nil},
+		},
+	}
+
+	engine := &HandlebarsEngine{
+		Helpers: map[string]interface{}{
+			"upper": func(value interface{}) interface{} { return value },
+			"pad": VariadicHelper(func(params []interface{}, options *raymond.Options) interface{} {
+				return params // javascript helpers are variadic
+			}),
+		},
+		Partials: map[string]string{"row": `<td>{{name}}</td><td>{{missingInPartial}}</td>`},
+	}
+
+	tests := []struct {
+		name     string
+		source   string
+		problems []string
+		paths    []string
+		helpers  []string
+	}{
+		{"known paths",
+			`{{title}} {{org.name}} {{org.inn}} {{#each rows}}{{name}} {{age}} {{note}}{{/each}}`,
+			nil,
+			[]string{"org.inn", "org.name", "rows", "rows[].age", "rows[].name", "rows[].note", "title"},
+			[]string{"each"}},
+		{"unknown path",
+			`{{title}}
+{{org.phone}} {{totla}}`,
+			[]string{"report.hbs:2:3: data path org.phone is absent in data",
+				"report.hbs:2:17: data path totla is absent in data",
+				"unused data key org.inn", "unused data key org.name", "unused data key rows"},
+			[]string{"org.phone", "title", "totla"},
+			nil},
+		{"each scope",
+			`{{title}}{{#each rows}}{{name}}{{age}}{{note}}{{title}}{{../title}}{{@index}}{{/each}}{{#with org}}{{name}}{{inn}}{{/with}}`,
+			[]string{"report.hbs:1:49: data path rows[].title is absent in data"},
+			[]string{"org", "org.inn", "org.name", "rows", "rows[].age", "rows[].name", "rows[].note", "rows[].title", "title"},
+			[]string{"each", "with"}},
+		{"block params",
+			`{{title}}{{org.name}}{{org.inn}}{{#each rows as |row|}}{{row.name}}{{row.age}}{{row.note}}{{/each}}`,
+			nil,
+			[]string{"org.inn", "org.name", "rows", "rows[].age", "rows[].name", "rows[].note", "title"},
+			[]string{"each"}},
+		{"unknown helper",
+			`{{title}}{{org}}{{rows}}{{format title "x"}}{{#repeat rows}}{{/repeat}}`,
+			[]string{"report.hbs:1:25: missing helper format", "report.hbs:1:45: missing helper repeat"},
+			[]string{"org", "rows", "title"},
+			[]string{"format", "repeat"}},
+		{"helpers use passed data",
+			`{{upper title}}{{pad org}}{{pad rows "x" 1}}{{upper}}`,
+			nil,
+			[]string{"org", "rows", "title"},
+			[]string{"pad", "upper"}},
+		{"partial",
+			`{{title}}{{org}}{{#each rows}}{{> row}}{{/each}}{{> missing}}`,
+			[]string{"row:1:24: data path rows[].missingInPartial is absent in data",
+				"report.hbs:1:49: missing partial missing",
+				"unused data key rows[].age", "unused data key rows[].note"},
+			[]string{"org", "rows", "rows[].missingInPartial", "rows[].name", "title"},
+			[]string{"each"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report, err := engine.Validate("report.hbs", test.source, data)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var problems []string
+
+			for _, problem := range report.Problems {
+				problems = append(problems, problem.String())
+			}
+
+			if !reflect.DeepEqual(problems, test.problems) {
+				t.Errorf("problems are %q, want %q", problems, test.problems)
+			}
+
+			if !reflect.DeepEqual(report.Paths, test.paths) {
+				t.Errorf("paths are %q, want %q", report.Paths, test.paths)
+			}
+
+			if !reflect.DeepEqual(report.Helpers, test.helpers) {
+				t.Errorf("helpers are %q, want %q", report.Helpers, test.helpers)
+			}
+
+			if report.HasProblems() != (len(test.problems) > 0) {
+				t.Errorf("report has problems is %v", report.HasProblems())
+			}
+		})
+	}
+}
+
+func TestHandlebarsValidateWithoutData(t *testing.T) {
+	engine := &HandlebarsEngine{}
+	report, err := engine.Validate("report.hbs", `{{title}}{{#each rows}}{{name}}{{/each}}`, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.HasProblems() || !reflect.DeepEqual(report.Paths, []string{"rows", "rows[].name", "title"}) {
+		t.Errorf("report is %+v, want paths listed without problems", report)
+	}
+
+	if _, err := engine.Validate("report.hbs", `{{#each rows}}`, nil); err == nil {
+		t.Error("template syntax error is not returned")
+	}
+}
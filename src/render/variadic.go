@@ -0,0 +1,311 @@
+package render
+
+import (
+	"errors"
+	"github.com/aymerick/raymond"
+	"github.com/aymerick/raymond/ast"
+	"github.com/aymerick/raymond/parser"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VariadicHelper Helper accepting any number of template arguments, like javascript helpers do.
+// raymond requires exact number of arguments, so every call of helper in template gets its index and
+// padding up to the longest call inserted before rendering. Errors of helper hold position of the call.
+// Arguments with paths missing in data are Undefined, null values are nil
+type VariadicHelper func(params []interface{}, options *raymond.Options) interface{}
+
+// undefined Type of Undefined
+type undefined struct{}
+
+// Undefined Argument of variadic helper with path missing in data, like javascript undefined.
+// raymond evaluates missing paths and null values to nil, other helpers get nil for both
+var Undefined interface{} = undefined{}
+
+var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+var optionsType = reflect.TypeOf((*raymond.Options)(nil))
+
+// variadicCalls Calls of variadic helpers in template and partials sources
+type variadicCalls struct {
+	arities map[string]int          // max number of arguments by helper name
+	calls   map[string][]helperCall // calls by file name, ordered by position
+	all     []helperCall            // calls of all files by index
+}
+
+// helperCall Call of variadic helper in source
+type helperCall struct {
+	name     string
+	end      int // offset of the end of helper name
+	params   int
+	parents  []string // paths of objects holding values of path arguments, 0 for other arguments
+	keys     []string // keys of path arguments in their parents, empty for other arguments
+	index    int      // index of call passed to helper
+	filename string
+	line     int
+	column   int
+}
+
+// findVariadicCalls Returns calls of variadic helpers in template and its partials (sources by file name)
+func findVariadicCalls(sources map[string]string, helpers map[string]interface{}) (*variadicCalls, error) {
+	result := &variadicCalls{arities: make(map[string]int), calls: make(map[string][]helperCall)}
+	filenames := make([]string, 0, len(sources))
+
+	for filename := range sources {
+		filenames = append(filenames, filename)
+	}
+
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		if err := result.collect(filename, sources[filename], helpers); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// collect Adds calls of variadic helpers in template source
+func (c *variadicCalls) collect(filename string, source string, helpers map[string]interface{}) error {
+	program, err := parser.Parse(source)
+
+	if err != nil {
+		return newTemplateError(filename, source, err)
+	}
+
+	var calls []helperCall
+
+	walker := &astWalker{
+		onExpression: func(expr *ast.Expression) {
+			name := expr.HelperName()
+
+			if _, ok := helpers[name].(VariadicHelper); !ok {
+				return
+			}
+
+			path := expr.Path.(*ast.PathExpression)
+			end := path.Loc.Pos + len(path.Original)
+			line, column := positionToLineColumn(source, path.Loc.Pos)
+			call := helperCall{name: name, end: end, params: len(expr.Params),
+				filename: filename, line: line, column: column}
+
+			for _, param := range expr.Params {
+				parent, key := parentPath(param)
+				call.parents = append(call.parents, parent)
+				call.keys = append(call.keys, key)
+			}
+
+			calls = append(calls, call)
+
+			if arity, seen := c.arities[name]; !seen || len(expr.Params) > arity {
+				c.arities[name] = len(expr.Params)
+			}
+		},
+	}
+	walker.walk(program)
+
+	sort.Slice(calls, func(i, j int) bool { return calls[i].end < calls[j].end })
+
+	for i := range calls {
+		calls[i].index = len(c.all)
+		c.all = append(c.all, calls[i])
+	}
+
+	c.calls[filename] = calls
+	return nil
+}
+
+// parentPath Returns path of object holding value of path argument and key of the value in it.
+// Data variables (@index, @root...) and other arguments get "0" and empty key
+func parentPath(param ast.Node) (string, string) {
+	path, ok := param.(*ast.PathExpression)
+
+	if !ok || path.Data || len(path.Parts) == 0 {
+		return "0", ""
+	}
+
+	last := len(path.Parts) - 1
+	parent := strings.TrimSuffix(strings.Repeat("../", path.Depth)+strings.Join(path.Parts[:last], "."), "/")
+
+	if parent == "" {
+		parent = "this"
+	}
+
+	key := path.Parts[last]
+
+	if strings.HasPrefix(key, "[") && strings.HasSuffix(key, "]") {
+		key = key[1 : len(key)-1]
+	}
+
+	return parent, key
+}
+
+// register Registers variadic helpers used in templates. Helpers take index of the call, parents of
+// path arguments and padding before the arguments, so every call of helper has the same number of arguments
+func (c *variadicCalls) register(tpl *raymond.Template, helpers map[string]interface{}) {
+	for name, arity := range c.arities {
+		helper := recoverHelper(name, helpers[name]).(VariadicHelper)
+		tpl.RegisterHelper(name, bindArity(helper, arity, c.all))
+	}
+}
+
+// bind Returns source with index of the call, parents of path arguments and padding inserted after helper name
+// of every call: {{format a}} becomes {{format 3 0 this 0 a}} when it is the call 3 and the longest call of
+// format has two arguments
+func (c *variadicCalls) bind(filename string, source string) *boundSource {
+	result := &boundSource{original: source}
+	var text strings.Builder
+	last := 0
+
+	for _, call := range c.calls[filename] {
+		padding := strings.Repeat(" 0", c.arities[call.name]-call.params)
+		inserted := " " + strconv.Itoa(call.index) + padding
+
+		for _, parent := range call.parents {
+			inserted += " " + parent
+		}
+
+		inserted += padding
+
+		text.WriteString(source[last:call.end])
+		text.WriteString(inserted)
+		result.insertions = append(result.insertions, insertion{pos: call.end, length: len(inserted)})
+		last = call.end
+	}
+
+	text.WriteString(source[last:])
+	result.text = text.String()
+	return result
+}
+
+// boundSource Template source with calls of variadic helpers bound to their number of arguments
+type boundSource struct {
+	original   string
+	text       string
+	insertions []insertion // ordered by position in original source
+}
+
+// insertion Text inserted into original source
+type insertion struct {
+	pos    int
+	length int
+}
+
+// templateError Converts raymond error of bound source into TemplateError with position in original source
+func (s *boundSource) templateError(filename string, err error) *TemplateError {
+	msg := posRegexp.ReplaceAllStringFunc(err.Error(), func(match string) string {
+		pos, _ := strconv.Atoi(posRegexp.FindStringSubmatch(match)[1])
+		return "Pos: " + strconv.Itoa(s.originalPos(pos))
+	})
+
+	return newTemplateError(filename, s.original, errors.New(msg))
+}
+
+// originalPos Returns offset in original source of offset in bound source
+func (s *boundSource) originalPos(pos int) int {
+	shift := 0
+
+	for _, inserted := range s.insertions {
+		if pos < inserted.pos+shift {
+			break
+		}
+
+		if pos < inserted.pos+shift+inserted.length {
+			return inserted.pos // inside inserted text
+		}
+
+		shift += inserted.length
+	}
+
+	return pos - shift
+}
+
+// bindArity Makes raymond helper function calling variadic helper with the last n of 2*arity+1 arguments,
+// where n is number of arguments of the call, which index is the first argument. Arguments are preceded
+// by parents of path arguments, nil arguments missing in their parents become Undefined. Errors of helper
+// become TemplateError with position of the call
+func bindArity(helper VariadicHelper, arity int, calls []helperCall) interface{} {
+	in := make([]reflect.Type, 2*arity+2)
+
+	for i := 0; i <= 2*arity; i++ {
+		in[i] = interfaceType
+	}
+
+	in[2*arity+1] = optionsType
+	funcType := reflect.FuncOf(in, []reflect.Type{interfaceType}, false)
+
+	return reflect.MakeFunc(funcType, func(args []reflect.Value) []reflect.Value {
+		index, _ := args[0].Interface().(int)
+		call := calls[index]
+		count := call.params
+		params := make([]interface{}, count)
+
+		for i := 0; i < count; i++ {
+			params[i] = args[2*arity+1-count+i].Interface()
+
+			if params[i] == nil && call.keys[i] != "" && !hasKey(args[arity+1-count+i].Interface(), call.keys[i]) {
+				params[i] = Undefined
+			}
+		}
+
+		options := args[2*arity+1].Interface().(*raymond.Options)
+		defer call.recoverError()
+		result := reflect.ValueOf(helper(params, options))
+
+		if !result.IsValid() {
+			result = reflect.Zero(interfaceType)
+		}
+
+		out := reflect.New(interfaceType).Elem()
+		out.Set(result)
+		return []reflect.Value{out}
+	}).Interface()
+}
+
+// recoverError Turns error panic of helper call into TemplateError with position of the call.
+// Errors of nested calls and layouts already hold their position
+func (c helperCall) recoverError() {
+	recovered := recover()
+
+	if recovered == nil {
+		return
+	}
+
+	if _, ok := recovered.(*TemplateError); ok {
+		panic(recovered)
+	}
+
+	if err, ok := recovered.(error); ok {
+		panic(&TemplateError{Filename: c.filename, Line: c.line, Column: c.column, Message: err.Error()})
+	}
+
+	panic(recovered)
+}
+
+// hasKey Checks map, struct or slice has value with key, like raymond looks fields up
+func hasKey(obj interface{}, key string) bool {
+	value := reflect.ValueOf(obj)
+
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return false
+		}
+
+		return value.MapIndex(reflect.ValueOf(key).Convert(value.Type().Key())).IsValid()
+	case reflect.Struct:
+		field, ok := value.Type().FieldByName(strings.Title(key))
+		return ok && field.PkgPath == ""
+	case reflect.Slice, reflect.Array:
+		index, err := strconv.Atoi(key)
+		return err == nil && index >= 0 && index < value.Len()
+	}
+
+	return false
+}
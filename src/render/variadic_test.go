@@ -0,0 +1,172 @@
+package render
+
+import (
+	"fmt"
+	"github.com/aymerick/raymond"
+	"testing"
+)
+
+// joinHelper Variadic helper joining its arguments, block helpers render their block after them
+var joinHelper = VariadicHelper(func(params []interface{}, options *raymond.Options) interface{} {
+	result := fmt.Sprint(len(params), params)
+
+	if options.Fn() != "" {
+		result += ":" + options.Fn()
+	}
+
+	return result
+})
+
+func TestHandlebarsVariadicHelperCalledWithDifferentArguments(t *testing.T) {
+	engine := &HandlebarsEngine{
+		Helpers:  map[string]interface{}{"join": joinHelper},
+		Partials: map[string]string{"row": `<td>{{join a b c}}</td>`},
+	}
+
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"no arguments", `{{join}}`, "0 []"},
+		{"literals", `{{join "x" 2}}`, "2 [x 2]"},
+		{"paths and hash", `{{join a b key=c}}`, "2 [1 2]"},
+		{"whitespace control", `{{~join a~}}`, "1 [1]"},
+		{"subexpression", `{{join (join a) b}}`, "2 [1 [1] 2]"},
+		{"block", `{{#join a}}in{{/join}}`, "1 [1]:in"},
+		{"partial with more arguments", `{{join a}}{{> row}}`, "1 [1]<td>3 [1 2 3]</td>"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := engine.Render("report.hbs", test.source, map[string]interface{}{"a": 1, "b": 2, "c": 3})
+
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHandlebarsVariadicHelperErrorPosition(t *testing.T) {
+	engine := &HandlebarsEngine{Helpers: map[string]interface{}{
+		"join":   joinHelper,
+		"strict": func(value string) string { return value },
+	}}
+
+	_, err := engine.Render("report.hbs", "{{join a}} {{join}}\n{{join a b}} {{strict}}", nil)
+
+	templateErr, ok := err.(*TemplateError)
+
+	if !ok {
+		t.Fatalf("expected TemplateError, got %T %v", err, err)
+	}
+
+	if templateErr.Line != 2 || templateErr.Column != 14 {
+		t.Errorf("error at %d:%d, want 2:14: %v", templateErr.Line, templateErr.Column, templateErr)
+	}
+}
+
+func TestBoundSourceOriginalPos(t *testing.T) {
+	call := helperCall{name: "join", end: 6, params: 1, index: 3, parents: []string{"this"}, keys: []string{"a"}}
+	calls := &variadicCalls{
+		arities: map[string]int{"join": 2},
+		calls:   map[string][]helperCall{"report.hbs": {call}},
+	}
+	bound := calls.bind("report.hbs", "{{join a}} x")
+
+	if bound.text != "{{join 3 0 this 0 a}} x" {
+		t.Fatalf("bound source %q", bound.text)
+	}
+
+	for pos, want := range map[int]int{0: 0, 6: 6, 8: 6, 16: 6, 18: 7, 22: 11} {
+		if got := bound.originalPos(pos); got != want {
+			t.Errorf("originalPos(%d) = %d, want %d", pos, got, want)
+		}
+	}
+}
+
+func TestHandlebarsVariadicHelperErrorHoldsCallPosition(t *testing.T) {
+	engine := &HandlebarsEngine{
+		Helpers: map[string]interface{}{
+			"join": joinHelper,
+			"fail": VariadicHelper(func(params []interface{}, options *raymond.Options) interface{} {
+				if len(params) > 0 {
+					panic(fmt.Errorf("failed with %v", params[0]))
+				}
+				return options.Fn()
+			}),
+		},
+		Partials: map[string]string{"row": "<td>\n  {{fail b}}</td>"},
+	}
+
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"{{join a}}\n{{join}} {{fail a}}", "report.hbs:2:12: failed with 1"},
+		{"{{#fail}}\n{{#join}}{{fail a}}{{/join}}{{/fail}}", "report.hbs:2:12: failed with 1"},
+		{"{{join a b}}{{> row}}", "row:2:5: failed with 2"},
+	}
+
+	for _, test := range tests {
+		_, err := engine.Render("report.hbs", test.source, map[string]interface{}{"a": 1, "b": 2})
+
+		if _, ok := err.(*TemplateError); !ok || err.Error() != test.want {
+			t.Errorf("%q error is %T %v, want %q", test.source, err, err, test.want)
+		}
+	}
+}
+
+func TestHandlebarsVariadicHelperGetsUndefinedForMissingPaths(t *testing.T) {
+	engine := &HandlebarsEngine{Helpers: map[string]interface{}{
+		"kind": VariadicHelper(func(params []interface{}, options *raymond.Options) interface{} {
+			switch {
+			case len(params) == 0:
+				return "none"
+			case params[0] == Undefined:
+				return "undefined"
+			case params[0] == nil:
+				return "null"
+			}
+			return "value"
+		}),
+	}}
+
+	data := map[string]interface{}{
+		"title": "Report",
+		"note":  nil,
+		"org":   map[string]interface{}{"name": "ООО", "phone": nil},
+		"rows":  []interface{}{map[string]interface{}{"name": "Ann", "note": nil}},
+	}
+
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{`{{kind title}} {{kind note}} {{kind missing}} {{kind}}`, "value null undefined none"},
+		{`{{kind org.name}} {{kind org.phone}} {{kind org.fax}} {{kind missing.path}} {{kind note.path}}`,
+			"value null undefined undefined undefined"},
+		{`{{kind "x"}} {{kind @index}} {{kind (kind missing)}} {{kind org.[phone]}}`, "value null value null"},
+		{`{{#each rows}}{{kind note}} {{kind fax}} {{kind ../org.phone}} {{kind ../org.fax}}{{/each}}`,
+			"null undefined null undefined"},
+		{`{{#each rows as |row|}}{{kind row.note}} {{kind row.fax}}{{/each}}`, "null undefined"},
+		{`{{#with org}}{{kind this.phone}} {{kind ./fax}}{{/with}}`, "null undefined"},
+	}
+
+	for _, test := range tests {
+		got, err := engine.Render("report.hbs", test.source, data)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != test.want {
+			t.Errorf("%s rendered %q, want %q", test.source, got, test.want)
+		}
+	}
+}
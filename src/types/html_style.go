@@ -9,12 +9,37 @@ type HtmlStyle struct {
 	WordWrap          bool
 	Width             float64
 	Height            float64
-	BorderInheritance bool
-	BorderStyle       bool
+	Borders           [4]Border // top, right, bottom, left
 	FontSize          float64
 	IsBold            bool
+	IsItalic          bool
+	IsStrike          bool
+	Underline         string // excel underline: single, double or empty
+	FontFamily        string
 	Colspan           int
+	Rowspan           int
 	VerticalAlign     string
 	CellValueType	  ValueType
 	BackgroundColor   string
+	Color             string
+}
+
+// Sides of cell borders in css order
+const (
+	BorderTop = iota
+	BorderRight
+	BorderBottom
+	BorderLeft
+)
+
+// Border Line of one side of cell
+type Border struct {
+	Style string  // css border style: none, solid, dashed, dotted, double...
+	Width float64 // css pixels
+	Color string  // excel color, empty for font color
+}
+
+// IsVisible Checks border is drawn
+func (b Border) IsVisible() bool {
+	return b.Style != "" && b.Style != "none" && b.Style != "hidden" && b.Width > 0
 }
@@ -0,0 +1,4 @@
+package types
+
+// StdioFilename File name meaning standard input for input files and standard output for output file
+const StdioFilename = "-"